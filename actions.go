@@ -0,0 +1,54 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+var workflowPatterns = []string{".github/workflows/*.yaml", ".github/workflows/*.yml"}
+
+// defineGitHubActionsTasks defines the lint-github-actions task if the repository
+// contains GitHub Actions workflows.
+func defineGitHubActionsTasks(conf *config) {
+	if len(globFiles(conf, workflowPatterns...)) == 0 {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-github-actions",
+		Usage: "Lints GitHub Actions workflows with actionlint.",
+		Action: func(a *goyek.A) {
+			shellcheck, ok := installShellcheck(a, conf)
+			if !ok {
+				return
+			}
+			actionlint := fmt.Sprintf("go run github.com/rhysd/actionlint/cmd/actionlint@%s -shellcheck=%s", conf.version("actionlint", verActionlint), shellQuote(shellcheck))
+			execFiles(a, actionlint, globFiles(conf, workflowPatterns...))
+		},
+	}))
+}
+
+// installShellcheck installs shellcheck into the artifacts directory, returning its path.
+// It is installed rather than run with go run since actionlint executes it directly for
+// the scripts of run steps.
+func installShellcheck(a *goyek.A, conf *config) (string, bool) {
+	a.Helper()
+
+	name := "shellcheck"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	bin, err := filepath.Abs(filepath.Join(conf.artifactsPath, "actionlint", name))
+	if err != nil {
+		a.Errorf("failed to resolve shellcheck path: %v", err)
+		return "", false
+	}
+	if !execCmd(a, "go install github.com/wasilibs/go-shellcheck/cmd/shellcheck@"+conf.version("shellcheck", verShellcheck), cmd.Env("GOBIN", filepath.Dir(bin))) {
+		return "", false
+	}
+	return bin, true
+}
@@ -0,0 +1,118 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+func (c *config) affectedOnly() bool {
+	return c.affected || *affectedFlag
+}
+
+func (c *config) affectedBase() string {
+	if c.affectedBaseRef == "" {
+		return "origin/main"
+	}
+	return c.affectedBaseRef
+}
+
+// affectedModules returns the modules in dirs with files changed since the merge base
+// with the base ref, or that depend on such a module. If a changed file is not in any
+// of the modules, or is a file in the root of the repository other than the Go files
+// of a root module, e.g. a shared lint configuration or go.work, all modules are
+// affected.
+func affectedModules(conf *config, dirs []string) ([]string, error) {
+	base, err := gitMergeBase(conf.affectedBase())
+	if err != nil {
+		return nil, err
+	}
+	files, err := gitChangedFiles(base)
+	if err != nil {
+		return nil, err
+	}
+
+	affected := map[string]bool{}
+	for _, f := range files {
+		if sharedFile(f) {
+			return dirs, nil
+		}
+		dir, ok := owningModule(dirs, filepath.FromSlash(f))
+		if !ok {
+			return dirs, nil
+		}
+		affected[dir] = true
+	}
+
+	// Modules are also affected when a module they require is, directly or through
+	// other modules.
+	paths := map[string]string{}
+	requires := map[string][]string{}
+	for _, dir := range dirs {
+		b, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+		mod, err := modfile.ParseLax(filepath.Join(dir, "go.mod"), b, nil)
+		if err != nil {
+			return nil, err
+		}
+		if mod.Module != nil {
+			paths[mod.Module.Mod.Path] = dir
+		}
+		for _, r := range mod.Require {
+			requires[dir] = append(requires[dir], r.Mod.Path)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, dir := range dirs {
+			if affected[dir] {
+				continue
+			}
+			for _, path := range requires[dir] {
+				if dep, ok := paths[path]; ok && affected[dep] {
+					affected[dir] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var res []string
+	for _, dir := range dirs {
+		if affected[dir] {
+			res = append(res, dir)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// sharedFile returns whether the changed file, relative to the working directory,
+// is in its root but not a Go file or go.mod or go.sum of a root module.
+func sharedFile(file string) bool {
+	if strings.Contains(file, "/") {
+		return false
+	}
+	return file != "go.mod" && file != "go.sum" && !strings.HasSuffix(file, ".go")
+}
+
+// owningModule returns the innermost of the module directories containing the file.
+func owningModule(dirs []string, file string) (string, bool) {
+	var owner string
+	var found bool
+	for _, dir := range dirs {
+		if dir != "." && file != dir && !strings.HasPrefix(file, dir+string(filepath.Separator)) {
+			continue
+		}
+		if !found || len(dir) > len(owner) || owner == "." {
+			owner, found = dir, true
+		}
+	}
+	return owner, found
+}
@@ -0,0 +1,31 @@
+package build
+
+import (
+	"fmt"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineAPIDiffTask defines the lint-apidiff task if enabled.
+func defineAPIDiffTask(conf *config) {
+	if !conf.apiDiff {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-apidiff",
+		Usage: "Checks for incompatible API changes since the latest released version.",
+		Action: func(a *goyek.A) {
+			// Without -base, gorelease compares against the latest version of the module
+			// available from the module proxy.
+			cmdLine := fmt.Sprintf("go run golang.org/x/exp/cmd/gorelease@%s", conf.version("gorelease", verGoRelease))
+			if !conf.apiDiffWarnOnly {
+				execCmd(a, cmdLine)
+				return
+			}
+			if err := tryExec(a, cmdLine); err != nil {
+				a.Logf("WARNING: gorelease reported incompatible changes: %v", err)
+			}
+		},
+	}))
+}
@@ -0,0 +1,137 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/goyek/goyek/v2"
+)
+
+const defaultLintBaseline = ".golangci-baseline.json"
+
+// baselineIssue identifies an existing lint issue in a baseline. Lines are not
+// included so that issues still match after unrelated edits to the file.
+type baselineIssue struct {
+	Linter string `json:"linter"`
+	File   string `json:"file"`
+	Text   string `json:"text"`
+}
+
+func (c *config) lintBaselinePath() string {
+	if c.lintBaseline != "" {
+		return c.lintBaseline
+	}
+	return defaultLintBaseline
+}
+
+// defineLintBaselineTask defines the generate-lint-baseline task.
+func defineLintBaselineTask(conf *config) {
+	goyek.Define(goyek.Task{
+		Name:  "generate-lint-baseline",
+		Usage: "Records current golangci-lint issues in the lint baseline so only new issues fail lint-go.",
+		Action: func(a *goyek.A) {
+			generateLintBaseline(a, conf)
+		},
+	})
+}
+
+func generateLintBaseline(a *goyek.A, conf *config) {
+	a.Helper()
+
+	dirs, err := conf.allGoModuleDirs()
+	if err != nil {
+		a.Fatalf("failed to find Go modules: %v", err)
+	}
+	var issues []lintIssue
+	for _, dir := range dirs {
+		issuesPath, err := moduleArtifact(conf, dir, filepath.Join(conf.artifactsPath, "golangci-lint.json"))
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(issuesPath), 0o755)
+		}
+		if err != nil {
+			a.Fatalf("failed to create out directory: %v", err)
+		}
+		_ = os.Remove(issuesPath)
+		cmdLine := golangciLintCommand(conf, dir) + " --out-format=" + shellQuote("json:"+issuesPath) + " --issues-exit-code=0 --max-issues-per-linter=0 --max-same-issues=0" + golangciLintTargets(conf)
+		if !execCmd(a, cmdLine, moduleOpts(dir, nil)...) {
+			return
+		}
+		if dryRun() {
+			continue
+		}
+		modIssues, err := readLintIssues(issuesPath)
+		if err != nil {
+			a.Fatalf("failed to read golangci-lint report: %v", err)
+		}
+		issues = append(issues, modIssues...)
+	}
+	if dryRun() {
+		return
+	}
+
+	baseline := make([]baselineIssue, 0, len(issues))
+	for _, i := range issues {
+		baseline = append(baseline, toBaselineIssue(i))
+	}
+	sort.Slice(baseline, func(i, j int) bool {
+		bi, bj := baseline[i], baseline[j]
+		if bi.File != bj.File {
+			return bi.File < bj.File
+		}
+		if bi.Linter != bj.Linter {
+			return bi.Linter < bj.Linter
+		}
+		return bi.Text < bj.Text
+	})
+	b, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		a.Fatalf("failed to encode lint baseline: %v", err)
+	}
+	path := conf.lintBaselinePath()
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		a.Fatalf("failed to write %s: %v", path, err)
+	}
+	a.Logf("wrote %s with %d issues", path, len(baseline))
+}
+
+// readLintBaseline returns the count of each issue in the baseline, or nil if there is
+// no baseline.
+func readLintBaseline(path string) (map[baselineIssue]int, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var issues []baselineIssue
+	if err := json.Unmarshal(b, &issues); err != nil {
+		return nil, err
+	}
+	counts := make(map[baselineIssue]int, len(issues))
+	for _, i := range issues {
+		counts[i]++
+	}
+	return counts, nil
+}
+
+// newLintIssues returns the issues not in the baseline. Each baseline entry matches
+// one issue, so additional occurrences of the same issue in a file are new.
+func newLintIssues(issues []lintIssue, baseline map[baselineIssue]int) []lintIssue {
+	var res []lintIssue
+	for _, i := range issues {
+		k := toBaselineIssue(i)
+		if baseline[k] > 0 {
+			baseline[k]--
+			continue
+		}
+		res = append(res, i)
+	}
+	return res
+}
+
+func toBaselineIssue(i lintIssue) baselineIssue {
+	return baselineIssue{Linter: i.FromLinter, File: filepath.ToSlash(i.Pos.Filename), Text: i.Text}
+}
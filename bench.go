@@ -0,0 +1,109 @@
+package build
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// runBenchmarks runs benchmarks, writing the results to bench.txt in the artifacts
+// directory and comparing them against a baseline if configured.
+func runBenchmarks(a *goyek.A, conf *config) {
+	a.Helper()
+
+	if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+		a.Fatalf("failed to create out directory: %v", err)
+	}
+
+	filter := conf.benchFilter
+	if filter == "" {
+		filter = "."
+	}
+
+	results := filepath.Join(conf.artifactsPath, "bench.txt")
+	var out strings.Builder
+	cmdLine := fmt.Sprintf("go test -run=^$ -bench=%s -benchmem -count=%d ./...", shellJoin([]string{filter}), conf.benchCount())
+	ok := execCmd(a, cmdLine, cmd.Stdout(io.MultiWriter(a.Output(), &out)))
+	if err := os.WriteFile(results, []byte(out.String()), 0o644); err != nil {
+		a.Fatalf("failed to write benchmark results: %v", err)
+	}
+	if !ok || conf.benchBaseline == "" {
+		return
+	}
+
+	var cmp strings.Builder
+	benchstat := fmt.Sprintf("go run golang.org/x/perf/cmd/benchstat@%s", conf.version("benchstat", verBenchstat))
+	if !execCmd(a, fmt.Sprintf("%s %s", benchstat, shellJoin([]string{conf.benchBaseline, results}))) {
+		return
+	}
+	if conf.benchRegressionThreshold <= 0 {
+		return
+	}
+	if !execCmd(a, fmt.Sprintf("%s -format=csv %s", benchstat, shellJoin([]string{conf.benchBaseline, results})), cmd.Stdout(&cmp)) {
+		return
+	}
+	checkBenchRegressions(a, conf, cmp.String())
+}
+
+// benchCount is the number of times to run each benchmark, enough for benchstat to
+// determine statistical significance when comparing against a baseline.
+func (c *config) benchCount() int {
+	if c.benchBaseline != "" {
+		return 10
+	}
+	return 1
+}
+
+// checkBenchRegressions fails the task if any statistically significant change in
+// benchstat CSV output is a regression beyond the threshold. Units reported by go test
+// -benchmem are better when lower, while rates such as B/s reported with b.SetBytes
+// are better when higher.
+func checkBenchRegressions(a *goyek.A, conf *config, report string) {
+	a.Helper()
+
+	r := csv.NewReader(strings.NewReader(report))
+	r.FieldsPerRecord = -1
+	// unit is the unit of the current table, set by its header row.
+	var unit string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			a.Errorf("failed to parse benchstat output: %v", err)
+			return
+		}
+		// Rows are name, old value, old CI, new value, new CI, delta, p-value, with the
+		// unit in place of the values in header rows without a name.
+		if len(rec) > 1 && rec[0] == "" && rec[1] != "" {
+			unit = rec[1]
+			continue
+		}
+		if len(rec) < 7 || rec[0] == "geomean" {
+			continue
+		}
+		delta := rec[len(rec)-2]
+		if !strings.HasSuffix(delta, "%") {
+			// Header rows or "~" for insignificant changes.
+			continue
+		}
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(delta, "%"), 64)
+		if err != nil {
+			continue
+		}
+		if strings.HasSuffix(unit, "/s") {
+			pct = -pct
+		}
+		if pct > conf.benchRegressionThreshold {
+			a.Errorf("benchmark %s regressed by %s, more than threshold %.1f%%", rec[0], delta, conf.benchRegressionThreshold)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+var brewFormulaTemplate = template.Must(template.New("formula").Parse(`# Code generated by go-build release-brew. DO NOT EDIT.
+class {{.Class}} < Formula
+  desc "{{.Description}}"
+  homepage "{{.Homepage}}"
+  version "{{.Version}}"
+{{- if .License}}
+  license "{{.License}}"
+{{- end}}
+{{range .OS}}
+  on_{{.Name}} do
+{{- range .Archives}}
+    on_{{.Arch}} do
+      url "{{.URL}}"
+      sha256 "{{.SHA256}}"
+    end
+{{- end}}
+  end
+{{end}}
+  def install
+{{- range .Binaries}}
+    bin.install "{{.}}"
+{{- end}}
+  end
+end
+`))
+
+type brewArchive struct {
+	Arch   string
+	URL    string
+	SHA256 string
+}
+
+type brewOS struct {
+	Name     string
+	Archives []brewArchive
+}
+
+// brewOSNames maps GOOS to the Homebrew name of the OS, for those Homebrew supports.
+var brewOSNames = map[string]string{"darwin": "macos", "linux": "linux"}
+
+// brewArchNames maps GOARCH to the Homebrew name of the architecture.
+var brewArchNames = map[string]string{"amd64": "intel", "arm64": "arm"}
+
+// releaseBrew renders a Homebrew formula installing the release archives for macOS and
+// Linux from GitHub releases, pushing it to the configured tap.
+func releaseBrew(a *goyek.A, conf *config) {
+	a.Helper()
+
+	repoURL := gitRepoURL()
+	if repoURL == "" {
+		a.Fatal("could not determine repository URL from origin remote")
+	}
+	tag := gitVersion()
+	project := projectName()
+	version := strings.TrimPrefix(tag, "v")
+	if dryRun() {
+		// The release the formula is rendered from is not packaged with -dry-run.
+		a.Log("Write (dry run): ", filepath.Join(conf.artifactsPath, "brew", project+".rb"))
+		return
+	}
+	releaseDir := filepath.Join(conf.artifactsPath, "release")
+	sums, err := readChecksums(filepath.Join(releaseDir, "SHA256SUMS"))
+	if err != nil {
+		a.Fatalf("failed to read checksums, run release first: %v", err)
+	}
+
+	oses := map[string]*brewOS{}
+	binaries := map[string]bool{}
+	for _, target := range conf.targets() {
+		goos, goarch, _ := strings.Cut(target, "/")
+		osName, arch := brewOSNames[goos], brewArchNames[goarch]
+		if osName == "" || arch == "" {
+			continue
+		}
+		name := fmt.Sprintf("%s_%s_%s_%s.tar.gz", project, version, goos, goarch)
+		sum, ok := sums[name]
+		if !ok {
+			a.Errorf("no checksum for archive %s", name)
+			continue
+		}
+		o, ok := oses[osName]
+		if !ok {
+			o = &brewOS{Name: osName}
+			oses[osName] = o
+		}
+		o.Archives = append(o.Archives, brewArchive{
+			Arch:   arch,
+			URL:    fmt.Sprintf("%s/releases/download/%s/%s", repoURL, tag, name),
+			SHA256: sum,
+		})
+		files, err := os.ReadDir(filepath.Join(conf.artifactsPath, "bin", goos+"_"+goarch))
+		if err != nil {
+			a.Errorf("failed to read binaries for %s: %v", target, err)
+			continue
+		}
+		for _, f := range files {
+			if !f.IsDir() {
+				binaries[f.Name()] = true
+			}
+		}
+	}
+	if len(oses) == 0 {
+		a.Fatal("no targets supported by Homebrew, add darwin or linux targets")
+	}
+
+	data := struct {
+		Class       string
+		Description string
+		Homepage    string
+		Version     string
+		License     string
+		OS          []brewOS
+		Binaries    []string
+	}{
+		Class:       brewClassName(project),
+		Description: conf.packageInfo.description,
+		Homepage:    conf.packageInfo.homepage,
+		Version:     version,
+		License:     conf.packageInfo.license,
+	}
+	if data.Homepage == "" {
+		data.Homepage = repoURL
+	}
+	for _, name := range []string{"macos", "linux"} {
+		if o, ok := oses[name]; ok {
+			data.OS = append(data.OS, *o)
+		}
+	}
+	for b := range binaries {
+		data.Binaries = append(data.Binaries, b)
+	}
+	sort.Strings(data.Binaries)
+
+	var formula strings.Builder
+	if err := brewFormulaTemplate.Execute(&formula, data); err != nil {
+		a.Fatalf("failed to render formula: %v", err)
+	}
+	brewDir := filepath.Join(conf.artifactsPath, "brew")
+	if err := os.MkdirAll(brewDir, 0o755); err != nil {
+		a.Fatalf("failed to create brew directory: %v", err)
+	}
+	file := filepath.Join(brewDir, project+".rb")
+	if err := os.WriteFile(file, []byte(formula.String()), 0o644); err != nil {
+		a.Fatalf("failed to write formula: %v", err)
+	}
+	a.Logf("wrote %s", file)
+
+	if conf.brewTap == "" {
+		return
+	}
+	tapDir := filepath.Join(brewDir, "tap")
+	if err := os.RemoveAll(tapDir); err != nil {
+		a.Fatalf("failed to clear tap directory: %v", err)
+	}
+	if !execCmd(a, fmt.Sprintf("git clone --depth=1 %s", shellJoin([]string{conf.brewTap, tapDir}))) {
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(tapDir, "Formula"), 0o755); err != nil {
+		a.Fatalf("failed to create Formula directory: %v", err)
+	}
+	if err := copyToFile(filepath.Join(tapDir, "Formula", project+".rb"), file); err != nil {
+		a.Fatalf("failed to copy formula: %v", err)
+	}
+	if !execCmd(a, "git add Formula", cmd.Dir(tapDir)) ||
+		!execCmd(a, fmt.Sprintf("git commit -m %s", shellJoin([]string{"Update " + project + " to " + version})), cmd.Dir(tapDir)) {
+		return
+	}
+	execCmd(a, "git push", cmd.Dir(tapDir))
+}
+
+// readChecksums reads a SHA256SUMS file into a map from file name to checksum.
+func readChecksums(file string) (map[string]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if sum, name, ok := strings.Cut(line, "  "); ok {
+			sums[name] = sum
+		}
+	}
+	return sums, nil
+}
+
+// brewClassName returns the Ruby class name Homebrew expects for a formula, e.g. GoBuild
+// for go-build.
+func brewClassName(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
@@ -0,0 +1,127 @@
+package build
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// buildBinaries builds the main packages under ./cmd for each configured target into
+// the bin directory of the artifacts directory.
+func buildBinaries(a *goyek.A, conf *config) {
+	a.Helper()
+
+	flags := buildFlags(conf)
+	for _, target := range conf.targets() {
+		goos, goarch, ok := strings.Cut(target, "/")
+		if !ok || goos == "" || goarch == "" {
+			a.Errorf("invalid target %q, must be of the form GOOS/GOARCH", target)
+			continue
+		}
+		// A trailing separator makes go build write each main package into the directory.
+		out := filepath.Join(conf.artifactsPath, "bin", goos+"_"+goarch) + string(filepath.Separator)
+		opts := []cmd.Option{cmd.Env("GOOS", goos), cmd.Env("GOARCH", goarch)}
+		if conf.staticBinaries {
+			opts = append(opts, cmd.Env("CGO_ENABLED", "0"))
+		}
+		if !execCmd(a, fmt.Sprintf("go build %s ./cmd/...", shellJoin(append(flags, "-o", out))), opts...) {
+			continue
+		}
+		if conf.staticBinaries {
+			verifyStatic(a, out)
+		}
+	}
+}
+
+// verifyStatic fails the task if any ELF binary in dir is dynamically linked. Other
+// binary formats are not checked.
+func verifyStatic(a *goyek.A, dir string) {
+	a.Helper()
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		a.Errorf("failed to read binaries: %v", err)
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		f, err := elf.Open(path)
+		if err != nil {
+			continue
+		}
+		var dynamic bool
+		for _, p := range f.Progs {
+			if p.Type == elf.PT_INTERP || p.Type == elf.PT_DYNAMIC {
+				dynamic = true
+			}
+		}
+		f.Close()
+		if dynamic {
+			a.Errorf("binary %s is not statically linked", path)
+		}
+	}
+}
+
+// buildFlags returns the flags to pass to go build for binaries.
+func buildFlags(conf *config) []string {
+	var flags []string
+	if conf.reproducibleBuilds {
+		flags = append(flags, "-trimpath", "-buildvcs=false")
+	}
+	if conf.staticBinaries {
+		// Use the pure Go implementations of packages that would otherwise prefer cgo.
+		flags = append(flags, "-tags=netgo,osusergo")
+	}
+	if ldflags := versionLDFlags(conf); ldflags != "" {
+		flags = append(flags, "-ldflags="+ldflags)
+	}
+	return flags
+}
+
+// versionLDFlags returns linker flags setting the version variables configured with
+// VersionInfo.
+func versionLDFlags(conf *config) string {
+	if conf.versionInfoPackage == "" {
+		return ""
+	}
+	// Like the version, the commit is unknown when not building from a git checkout.
+	commit, _ := gitCommit()
+	vars := []string{
+		"Version=" + gitVersion(),
+		"Commit=" + commit,
+		"Date=" + buildTime(conf).UTC().Format(time.RFC3339),
+	}
+	var ldflags []string
+	for _, v := range vars {
+		ldflags = append(ldflags, "-X "+conf.versionInfoPackage+"."+v)
+	}
+	return strings.Join(ldflags, " ")
+}
+
+// buildTime returns the time to record in build outputs. For reproducible builds, this
+// is SOURCE_DATE_EPOCH if set and otherwise the time of the current commit, so it is
+// the same for all builds of a commit.
+func buildTime(conf *config) time.Time {
+	if !conf.reproducibleBuilds {
+		return time.Now()
+	}
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if sec, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			return time.Unix(sec, 0)
+		}
+	}
+	if t, err := gitCommitTime(); err == nil {
+		return t
+	}
+	return time.Unix(0, 0)
+}
@@ -2,18 +2,21 @@ module build
 
 go 1.22
 
-require (
-	github.com/curioswitch/go-build v0.0.0-20220104000000-000000000000
-	github.com/goyek/x v0.1.7
-)
+require github.com/curioswitch/go-build v0.0.0-20220104000000-000000000000
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.6.1 // indirect
 	github.com/fatih/color v1.16.0 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/goyek/goyek/v2 v2.1.0 // indirect
+	github.com/goyek/x v0.1.7 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-shellwords v1.0.12 // indirect
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1 // indirect
+	golang.org/x/mod v0.17.0 // indirect
 	golang.org/x/sys v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/curioswitch/go-build => ../
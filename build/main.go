@@ -1,14 +1,10 @@
 package main
 
-import (
-	"github.com/goyek/x/boot"
-
-	"github.com/curioswitch/go-build"
-)
+import "github.com/curioswitch/go-build"
 
 func main() {
 	build.DefineTasks(
 		build.LocalPackagePrefix("github.com/curioswitch/go-build"),
 	)
-	boot.Main()
+	build.Main()
 }
@@ -0,0 +1,271 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// change is a commit included in a changelog.
+type change struct {
+	sha      string
+	typ      string
+	scope    string
+	subject  string
+	pr       string
+	author   string
+	breaking bool
+}
+
+// changelogSection is a section of a changelog listing commits of a type.
+type changelogSection struct {
+	typ     string
+	heading string
+}
+
+// defaultChangelogSections are the sections of a changelog by default, in order.
+// Commits of other types or not following Conventional Commits are listed under
+// "Other Changes".
+var defaultChangelogSections = []changelogSection{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+}
+
+// changelogSections returns the sections of changelogs, the default with any
+// configured with ChangelogSection.
+func (c *config) changelogSections() []changelogSection {
+	var sections []changelogSection
+	for _, s := range append(append([]changelogSection(nil), defaultChangelogSections...), c.extraChangelogSections...) {
+		replaced := false
+		for i := range sections {
+			if sections[i].typ == s.typ {
+				sections[i] = s
+				replaced = true
+			}
+		}
+		if !replaced {
+			sections = append(sections, s)
+		}
+	}
+	return sections
+}
+
+// pullRequestRef matches the reference to a pull request GitHub appends to the subject
+// of squashed commits.
+var pullRequestRef = regexp.MustCompile(` \(#(\d+)\)$`)
+
+// defineChangelogTask defines the generate-changelog task when in a git repository.
+func defineChangelogTask(conf *config) {
+	if !inGitRepo() {
+		return
+	}
+
+	goyek.Define(goyek.Task{
+		Name:  "generate-changelog",
+		Usage: "Generates changelog entries from commits since the last tag.",
+		Action: func(a *goyek.A) {
+			generateChangelog(a, conf)
+		},
+	})
+}
+
+func generateChangelog(a *goyek.A, conf *config) {
+	a.Helper()
+
+	version, changes, err := gitChanges()
+	if err != nil {
+		a.Fatal(err)
+	}
+	section := renderChangelog(version, changes, conf.changelogSections(), gitRepoURL())
+
+	file := conf.changelogFile
+	if file == "" {
+		file = filepath.Join(conf.artifactsPath, "CHANGELOG.md")
+	}
+	if dryRun() {
+		a.Log("Write (dry run): ", file)
+		return
+	}
+	if conf.changelogFile == "" {
+		if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+			a.Fatalf("failed to create out directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(section), 0o644); err != nil {
+			a.Fatalf("failed to write changelog: %v", err)
+		}
+		a.Logf("wrote %s", file)
+		return
+	}
+
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		a.Fatalf("failed to read changelog: %v", err)
+	}
+	if err := os.WriteFile(file, []byte(prependChangelog(string(existing), section)), 0o644); err != nil {
+		a.Fatalf("failed to write changelog: %v", err)
+	}
+	a.Logf("updated %s", file)
+}
+
+// gitChanges returns the version of the current commit, its tag or "Unreleased", and
+// the changes since the previous tag.
+func gitChanges() (string, []change, error) {
+	version := "Unreleased"
+	rev := "HEAD"
+	if out, err := exec.Command("git", "describe", "--tags", "--exact-match", "HEAD").Output(); err == nil {
+		version = strings.TrimSpace(string(out))
+		rev = "HEAD^"
+	}
+
+	logRange := "HEAD"
+	if out, err := exec.Command("git", "describe", "--tags", "--abbrev=0", rev).Output(); err == nil {
+		logRange = strings.TrimSpace(string(out)) + "..HEAD"
+	}
+	out, err := exec.Command("git", "log", "--no-merges", "--format=%h%x00%an%x00%s%x00%b%x1e", logRange).Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("listing commits: %w", err)
+	}
+
+	var changes []change
+	for _, rec := range strings.Split(string(out), "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(rec), "\x00", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		c := change{sha: fields[0], author: fields[1], subject: fields[2]}
+		if m := pullRequestRef.FindStringSubmatch(c.subject); m != nil {
+			c.pr = m[1]
+			c.subject = strings.TrimSuffix(c.subject, m[0])
+		}
+		if m := conventionalCommit.FindStringSubmatch(c.subject); m != nil {
+			c.typ, c.scope = m[1], m[2]
+			header, _, _ := strings.Cut(c.subject, ":")
+			c.breaking = strings.HasSuffix(header, "!")
+			c.subject = strings.TrimSpace(c.subject[len(header)+1:])
+		}
+		if len(fields) == 4 && strings.Contains(fields[3], "BREAKING CHANGE") {
+			c.breaking = true
+		}
+		changes = append(changes, c)
+	}
+	return version, changes, nil
+}
+
+// renderChangelog renders the changes as a Markdown changelog section for version,
+// linking commits and pull requests if the repository URL is known.
+func renderChangelog(version string, changes []change, sections []changelogSection, repoURL string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s", version)
+	if version != "Unreleased" {
+		fmt.Fprintf(&sb, " (%s)", time.Now().UTC().Format("2006-01-02"))
+	}
+	sb.WriteString("\n")
+	writeChanges(&sb, changes, sections, repoURL)
+	return sb.String()
+}
+
+// writeChanges writes the changes grouped into sections with level 3 headings.
+func writeChanges(sb *strings.Builder, changes []change, sections []changelogSection, repoURL string) {
+	writeSection := func(heading string, include func(c change) bool) {
+		var lines []string
+		for _, c := range changes {
+			if include(c) {
+				lines = append(lines, formatChange(c, repoURL))
+			}
+		}
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(sb, "\n### %s\n\n", heading)
+		for _, line := range lines {
+			fmt.Fprintf(sb, "- %s\n", line)
+		}
+	}
+
+	writeSection("Breaking Changes", func(c change) bool { return c.breaking })
+	known := map[string]bool{}
+	for _, s := range sections {
+		typ := s.typ
+		known[typ] = true
+		writeSection(s.heading, func(c change) bool { return !c.breaking && c.typ == typ })
+	}
+	writeSection("Other Changes", func(c change) bool { return !c.breaking && !known[c.typ] })
+}
+
+func formatChange(c change, repoURL string) string {
+	var sb strings.Builder
+	if c.scope != "" {
+		fmt.Fprintf(&sb, "**%s:** ", c.scope)
+	}
+	sb.WriteString(c.subject)
+	if repoURL == "" {
+		fmt.Fprintf(&sb, " (%s)", c.sha)
+		if c.pr != "" {
+			fmt.Fprintf(&sb, " (#%s)", c.pr)
+		}
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, " ([%s](%s/commit/%s))", c.sha, repoURL, c.sha)
+	if c.pr != "" {
+		fmt.Fprintf(&sb, " ([#%s](%s/pull/%s))", c.pr, repoURL, c.pr)
+	}
+	return sb.String()
+}
+
+// prependChangelog adds section before the existing sections of changelog, replacing
+// the first section if it is for the same version, e.g. when regenerating, or for
+// unreleased changes, which are now included in the release. If changelog does not
+// start with a title, section is added at the top.
+func prependChangelog(changelog string, section string) string {
+	if changelog == "" {
+		return "# Changelog\n\n" + section
+	}
+	header, rest := "", changelog
+	if strings.HasPrefix(changelog, "# ") {
+		header, rest = changelog, ""
+		if i := strings.Index(changelog, "\n## "); i >= 0 {
+			header, rest = changelog[:i+1], changelog[i+1:]
+		}
+	}
+	heading, _, _ := strings.Cut(section, "\n")
+	version := strings.Fields(heading)[1]
+	if first, _, _ := strings.Cut(rest, "\n"); strings.HasPrefix(first, "## ") && len(strings.Fields(first)) > 1 && (strings.Fields(first)[1] == version || strings.Fields(first)[1] == "Unreleased") {
+		if i := strings.Index(rest, "\n## "); i >= 0 {
+			rest = rest[i+1:]
+		} else {
+			rest = ""
+		}
+	}
+	if rest != "" {
+		section += "\n"
+	}
+	if header == "" {
+		return section + rest
+	}
+	return strings.TrimRight(header, "\n") + "\n\n" + section + rest
+}
+
+// gitRepoURL returns the web URL of the repository from the origin remote, or
+// empty if it is not hosted on a known forge.
+func gitRepoURL() string {
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	url := strings.TrimSuffix(strings.TrimSpace(string(out)), ".git")
+	if rest, ok := strings.CutPrefix(url, "git@"); ok {
+		host, path, _ := strings.Cut(rest, ":")
+		url = "https://" + host + "/" + path
+	}
+	if !strings.HasPrefix(url, "https://") {
+		return ""
+	}
+	return url
+}
@@ -0,0 +1,168 @@
+package build
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goyek/goyek/v2"
+)
+
+const (
+	// CIGitHubActions generates a GitHub Actions workflow at .github/workflows/ci.yaml.
+	CIGitHubActions = "github"
+	// CIGitLab generates a GitLab CI configuration at .gitlab-ci.yml.
+	CIGitLab = "gitlab"
+)
+
+var githubWorkflowTemplate = template.Must(template.New("ci.yaml").Parse(`# Code generated by go-build generate-ci. DO NOT EDIT.
+name: CI
+on:
+  push:
+    branches:
+      - main
+    tags:
+      - v*
+  pull_request:
+
+jobs:
+  check:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - run: go run {{.Build}} -check format
+      - run: go run {{.Build}} check
+{{- if .Release}}
+
+  release:
+    if: startsWith(github.ref, 'refs/tags/')
+    needs: check
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+      - uses: actions/setup-go@v5
+        with:
+          go-version-file: go.mod
+      - run: go run {{.Build}} release
+      - uses: actions/upload-artifact@v4
+        with:
+          name: release
+          path: {{.ReleaseDir}}
+{{- end}}
+`))
+
+var gitlabCITemplate = template.Must(template.New(".gitlab-ci.yml").Parse(`# Code generated by go-build generate-ci. DO NOT EDIT.
+image: golang:{{.GoVersion}}
+
+stages:
+  - check
+{{- if .Release}}
+  - release
+{{- end}}
+
+check:
+  stage: check
+  script:
+    - go run {{.Build}} -check format
+    - go run {{.Build}} check
+{{- if .Release}}
+
+release:
+  stage: release
+  rules:
+    - if: $CI_COMMIT_TAG
+  variables:
+    GIT_DEPTH: 0
+  script:
+    - go run {{.Build}} release
+  artifacts:
+    paths:
+      - {{.ReleaseDir}}
+{{- end}}
+`))
+
+// defineCITask defines the generate-ci task if any CI providers are configured.
+func defineCITask(conf *config) {
+	if len(conf.ciProviders) == 0 {
+		return
+	}
+
+	RegisterGenerateTask(goyek.Define(goyek.Task{
+		Name:  "generate-ci",
+		Usage: "Generates CI workflows running the build tasks.",
+		Action: func(a *goyek.A) {
+			generateCI(a, conf)
+		},
+	}))
+}
+
+func generateCI(a *goyek.A, conf *config) {
+	a.Helper()
+
+	data := struct {
+		Build      string
+		Release    bool
+		ReleaseDir string
+		GoVersion  string
+	}{
+		Build:      conf.buildPkg(),
+		Release:    dirExists("cmd"),
+		ReleaseDir: filepath.ToSlash(filepath.Join(conf.artifactsPath, "release")),
+		GoVersion:  goDirective(),
+	}
+
+	for _, provider := range conf.ciProviders {
+		var file string
+		var tmpl *template.Template
+		switch provider {
+		case CIGitHubActions:
+			file, tmpl = filepath.Join(".github", "workflows", "ci.yaml"), githubWorkflowTemplate
+		case CIGitLab:
+			file, tmpl = ".gitlab-ci.yml", gitlabCITemplate
+		default:
+			a.Errorf("unknown CI provider %q", provider)
+			continue
+		}
+
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			a.Errorf("failed to render %s: %v", file, err)
+			continue
+		}
+		if dryRun() {
+			a.Log("Write (dry run): ", file)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			a.Errorf("failed to create directory for %s: %v", file, err)
+			continue
+		}
+		if err := os.WriteFile(file, []byte(sb.String()), 0o644); err != nil {
+			a.Errorf("failed to write %s: %v", file, err)
+		}
+	}
+}
+
+// goDirective returns the Go version declared in go.mod in the working directory.
+func goDirective() string {
+	f, err := os.Open("go.mod")
+	if err != nil {
+		return "latest"
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(s.Text()), "go "); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return "latest"
+}
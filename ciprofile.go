@@ -0,0 +1,69 @@
+package build
+
+import (
+	"os"
+	"time"
+
+	"github.com/goyek/x/color"
+)
+
+type ciMode int
+
+const (
+	ciModeDetect ciMode = iota
+	ciModeForceCI
+	ciModeForceLocal
+)
+
+// ciEnvVars are environment variables set by CI providers.
+var ciEnvVars = []string{
+	"CI",
+	"GITHUB_ACTIONS",
+	"GITLAB_CI",
+	"BUILDKITE",
+	"CIRCLECI",
+	"JENKINS_URL",
+	"TF_BUILD",
+}
+
+// inCI returns whether the build is running in CI based on the environment.
+func inCI() bool {
+	for _, env := range ciEnvVars {
+		if v := os.Getenv(env); v != "" && v != "false" && v != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *config) ci() bool {
+	switch c.ciMode {
+	case ciModeForceCI:
+		return true
+	case ciModeForceLocal:
+		return false
+	default:
+		return inCI()
+	}
+}
+
+// applyCIProfile switches defaults for running in CI: formatting is only checked,
+// colors are disabled, and the build and JUnit reports are written.
+func applyCIProfile(conf *config) {
+	if !conf.ci() {
+		return
+	}
+	conf.formatCheck = true
+	conf.buildReport = true
+	conf.junitReport = true
+	color.NoColor()
+}
+
+// defaultTimeout returns the timeout for long-running tools like go test and
+// golangci-lint, which is stricter in CI to not stall jobs on hung tools.
+func (c *config) defaultTimeout() time.Duration {
+	if c.ci() {
+		return 10 * time.Minute
+	}
+	return 20 * time.Minute
+}
@@ -0,0 +1,161 @@
+// Command gobuild scaffolds projects using go-build.
+//
+// Running gobuild init in the root of a Go module creates a build module with a goyek
+// entrypoint calling DefineTasks and a starter .gobuild.yaml, after which tasks can be
+// run with go run ./build.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"text/template"
+)
+
+const goBuildModule = "github.com/curioswitch/go-build"
+
+var mainTemplate = template.Must(template.New("main.go").Parse(`package main
+
+import "github.com/curioswitch/go-build"
+
+func main() {
+	build.DefineTasks({{if .Module}}
+		build.LocalPackagePrefix("{{.Module}}"),
+	{{end}})
+	build.Main()
+}
+`))
+
+const configFile = `# Configuration for go-build tasks, see https://github.com/curioswitch/go-build.
+# Values here take precedence over options passed to DefineTasks.
+
+# artifactsPath: out
+# defaultTask: check
+# excludeTasks:
+#   - lint-vuln
+# toolVersions:
+#   golangci-lint: v1.58.1
+# targets:
+#   - linux/amd64
+#   - darwin/arm64
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: gobuild init [flags]\n\nScaffolds a go-build build module in the current directory.\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	dir := flag.String("dir", "build", "the `directory` to create the build module in")
+	flag.Parse()
+
+	if flag.NArg() != 1 || flag.Arg(0) != "init" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := initProject(*dir); err != nil {
+		fmt.Fprintf(os.Stderr, "gobuild: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func initProject(dir string) error {
+	mainFile := filepath.Join(dir, "main.go")
+	for _, f := range []string{mainFile, filepath.Join(dir, "go.mod")} {
+		if _, err := os.Stat(f); err == nil {
+			return fmt.Errorf("%s already exists", f)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	var main strings.Builder
+	if err := mainTemplate.Execute(&main, struct{ Module string }{modulePath()}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(mainFile, []byte(main.String()), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("created %s\n", mainFile)
+
+	if _, err := os.Stat(".gobuild.yaml"); os.IsNotExist(err) {
+		if err := os.WriteFile(".gobuild.yaml", []byte(configFile), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created .gobuild.yaml")
+	}
+
+	// The build module is kept separate so build dependencies do not affect the
+	// project's go.mod.
+	if err := run(dir, "go", "mod", "init", "build"); err != nil {
+		return err
+	}
+	if err := run(dir, "go", "get", goBuildModule+"@"+goBuildVersion()); err != nil {
+		return err
+	}
+	if err := run(dir, "go", "mod", "tidy"); err != nil {
+		return err
+	}
+	if _, err := os.Stat("go.work"); err == nil {
+		if err := run(".", "go", "work", "use", dir); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\nrun tasks with go run ./%s, e.g. go run ./%s check\n", filepath.ToSlash(dir), filepath.ToSlash(dir))
+	return nil
+}
+
+// pseudoVersion matches the suffix of pseudo-versions stamped into binaries built from
+// a checkout rather than installed from a release.
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}(\+dirty)?$`)
+
+// goBuildVersion returns the version of go-build to use, the version of this command
+// if installed from a release.
+func goBuildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path != goBuildModule {
+		return "latest"
+	}
+	v := info.Main.Version
+	if v == "" || v == "(devel)" || pseudoVersion.MatchString(v) {
+		return "latest"
+	}
+	return v
+}
+
+// modulePath returns the module path declared in go.mod in the working directory.
+func modulePath() string {
+	f, err := os.Open("go.mod")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if mod, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(mod), `"`)
+		}
+	}
+	return ""
+}
+
+func run(dir string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running %s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
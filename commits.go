@@ -0,0 +1,83 @@
+package build
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defaultCommitTypes are the commit types allowed by lint-commits by default, those
+// of the Angular convention commonly used with Conventional Commits.
+var defaultCommitTypes = []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+// conventionalCommit matches the header of a commit message following Conventional
+// Commits, capturing the type and scope.
+var conventionalCommit = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]+)\))?!?: \S`)
+
+// defineCommitsTask defines the lint-commits task if enabled.
+func defineCommitsTask(conf *config) {
+	if !conf.conventionalCommits {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-commits",
+		Usage: "Checks commit messages of the current branch follow Conventional Commits.",
+		Action: func(a *goyek.A) {
+			lintCommits(a, conf)
+		},
+	}))
+}
+
+func lintCommits(a *goyek.A, conf *config) {
+	a.Helper()
+
+	if !inGitRepo() {
+		a.Skip("not in a git repository")
+	}
+
+	// The base ref is often missing in shallow or single-branch clones, where there is
+	// nothing to compare against.
+	if !gitRefExists(conf.commitsBase()) {
+		a.Skipf("base ref %s not found, fetch it to lint commits", conf.commitsBase())
+	}
+	base, err := gitMergeBase(conf.commitsBase())
+	if err != nil {
+		a.Fatal(err)
+	}
+	out, err := exec.Command("git", "log", "--no-merges", "--format=%h %s", base+"..HEAD").Output()
+	if err != nil {
+		a.Fatalf("failed to list commits: %v", err)
+	}
+
+	types := conf.commitTypes
+	if len(types) == 0 {
+		types = defaultCommitTypes
+	}
+	allowedTypes := stringSet(types)
+	allowedScopes := stringSet(conf.commitScopes)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		sha, subject, _ := strings.Cut(line, " ")
+		m := conventionalCommit.FindStringSubmatch(subject)
+		switch {
+		case m == nil:
+			a.Errorf("commit %s does not follow Conventional Commits: %q", sha, subject)
+		case !allowedTypes[m[1]]:
+			a.Errorf("commit %s has type %q, must be one of %s", sha, m[1], strings.Join(types, ", "))
+		case m[2] != "" && len(allowedScopes) > 0 && !allowedScopes[m[2]]:
+			a.Errorf("commit %s has scope %q, must be one of %s", sha, m[2], strings.Join(conf.commitScopes, ", "))
+		}
+	}
+}
+
+func (c *config) commitsBase() string {
+	if c.commitsBaseRef == "" {
+		return "origin/main"
+	}
+	return c.commitsBaseRef
+}
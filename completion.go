@@ -0,0 +1,128 @@
+package build
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// completionFlag is a flag offered in completions.
+type completionFlag struct {
+	name    string
+	usage   string
+	boolean bool
+}
+
+// writeCompletion writes a completion script for shell into the artifacts directory,
+// completing the names of the defined tasks and flags for the command.
+func writeCompletion(a *goyek.A, conf *config, shell string, command string) {
+	a.Helper()
+
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+
+	var tasks []*goyek.DefinedTask
+	for _, t := range goyek.Tasks() {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Name() < tasks[j].Name()
+	})
+
+	var flags []completionFlag
+	flag.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{name: f.Name, usage: usage, boolean: ok && b.IsBoolFlag()})
+	})
+
+	var sb strings.Builder
+	switch shell {
+	case "bash":
+		writeBashCompletion(&sb, command, tasks, flags)
+	case "zsh":
+		writeZshCompletion(&sb, command, tasks, flags)
+	case "fish":
+		writeFishCompletion(&sb, command, tasks, flags)
+	default:
+		a.Fatalf("unsupported shell %q, must be bash, zsh, or fish", shell)
+	}
+	// The script is written to a file rather than printed since the build prints the
+	// status of the run after tasks finish.
+	dir := filepath.Join(conf.artifactsPath, "completion")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		a.Fatalf("failed to create completion directory: %v", err)
+	}
+	path := filepath.Join(dir, command+"."+shell)
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		a.Fatalf("failed to write completion script: %v", err)
+	}
+	// Written directly to the flow output so the path is printed without -v.
+	fmt.Fprintf(goyek.Output(), "wrote %s completion for %s to %s\n", shell, command, path)
+}
+
+func writeBashCompletion(sb *strings.Builder, command string, tasks []*goyek.DefinedTask, flags []completionFlag) {
+	taskNames := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		taskNames = append(taskNames, t.Name())
+	}
+	flagNames := make([]string, 0, len(flags))
+	for _, f := range flags {
+		flagNames = append(flagNames, "-"+f.name)
+	}
+	fn := "_" + identifier(command) + "_completion"
+
+	fmt.Fprintf(sb, "# bash completion for %s generated by go-build, regenerate when tasks change.\n", command)
+	fmt.Fprintf(sb, "%s() {\n", fn)
+	sb.WriteString("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	sb.WriteString("\tif [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(sb, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(flagNames, " ")))
+	sb.WriteString("\telse\n")
+	fmt.Fprintf(sb, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(taskNames, " ")))
+	sb.WriteString("\tfi\n}\n")
+	fmt.Fprintf(sb, "complete -F %s %s\n", fn, shellQuote(command))
+}
+
+func writeZshCompletion(sb *strings.Builder, command string, tasks []*goyek.DefinedTask, flags []completionFlag) {
+	fmt.Fprintf(sb, "#compdef %s\n# zsh completion for %s generated by go-build, regenerate when tasks change.\n\n", command, command)
+	sb.WriteString("local -a tasks\ntasks=(\n")
+	for _, t := range tasks {
+		desc := strings.ReplaceAll(t.Name(), ":", `\:`)
+		if t.Usage() != "" {
+			desc += ":" + t.Usage()
+		}
+		fmt.Fprintf(sb, "\t%s\n", shellQuote(desc))
+	}
+	sb.WriteString(")\n\n_arguments \\\n")
+	for _, f := range flags {
+		usage := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(f.usage)
+		spec := fmt.Sprintf("-%s[%s]", f.name, usage)
+		if !f.boolean {
+			spec += ":" + f.name + ":"
+		}
+		fmt.Fprintf(sb, "\t%s \\\n", shellQuote(spec))
+	}
+	sb.WriteString("\t'*:task:{_describe task tasks}'\n")
+}
+
+func writeFishCompletion(sb *strings.Builder, command string, tasks []*goyek.DefinedTask, flags []completionFlag) {
+	cmd := shellQuote(command)
+	fmt.Fprintf(sb, "# fish completion for %s generated by go-build, regenerate when tasks change.\n", command)
+	fmt.Fprintf(sb, "complete -c %s -f\n", cmd)
+	for _, t := range tasks {
+		fmt.Fprintf(sb, "complete -c %s -a %s -d %s\n", cmd, shellQuote(t.Name()), shellQuote(t.Usage()))
+	}
+	for _, f := range flags {
+		line := fmt.Sprintf("complete -c %s -o %s -d %s", cmd, shellQuote(f.name), shellQuote(f.usage))
+		if !f.boolean {
+			line += " -r"
+		}
+		sb.WriteString(line + "\n")
+	}
+}
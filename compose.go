@@ -0,0 +1,56 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// withCompose runs fn while the services in the configured compose file are running,
+// tearing them down afterwards. If fn returns false, the service logs are saved to the
+// artifacts directory for debugging. Returns the result of fn.
+func withCompose(a *goyek.A, conf *config, fn func() bool) bool {
+	a.Helper()
+
+	if conf.composeFile == "" {
+		return fn()
+	}
+
+	compose := "docker compose -f " + shellJoin([]string{conf.composeFile})
+	a.Cleanup(func() {
+		execCmd(a, compose+" down -v --remove-orphans")
+	})
+	if !execCmd(a, compose+" up -d --wait") {
+		saveComposeLogs(a, conf, compose)
+		return false
+	}
+
+	if !fn() {
+		saveComposeLogs(a, conf, compose)
+		return false
+	}
+	return true
+}
+
+func saveComposeLogs(a *goyek.A, conf *config, compose string) {
+	a.Helper()
+
+	logsDir := filepath.Join(conf.artifactsPath, "logs")
+	if err := os.MkdirAll(logsDir, 0o755); err != nil {
+		a.Errorf("failed to create logs directory: %v", err)
+		return
+	}
+	var logs strings.Builder
+	if !execCmd(a, compose+" logs --no-color --timestamps", cmd.Stdout(&logs)) {
+		return
+	}
+	file := filepath.Join(logsDir, "compose.log")
+	if err := os.WriteFile(file, []byte(logs.String()), 0o644); err != nil {
+		a.Errorf("failed to write compose logs: %v", err)
+		return
+	}
+	a.Logf("compose service logs written to %s", file)
+}
@@ -0,0 +1,72 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileName is the name of the optional configuration file read from the
+// working directory by DefineTasks.
+const configFileName = ".gobuild.yaml"
+
+// configFile is the format of the configuration file.
+type configFile struct {
+	ArtifactsPath  string            `yaml:"artifactsPath"`
+	DefaultTask    string            `yaml:"defaultTask"`
+	Aliases        map[string]string `yaml:"aliases"`
+	ExcludeTasks   []string          `yaml:"excludeTasks"`
+	ToolVersions   map[string]string `yaml:"toolVersions"`
+	IgnorePaths    []string          `yaml:"ignorePaths"`
+	ExcludeModules []string          `yaml:"excludeModules"`
+	Targets        []string          `yaml:"targets"`
+}
+
+// loadConfigFile merges the configuration file into conf if it exists. Values in the
+// file take precedence over Options for settings with a single value and are appended
+// for lists.
+func loadConfigFile(conf *config) error {
+	b, err := os.ReadFile(configFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", configFileName, err)
+	}
+
+	var f configFile
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing %s: %w", configFileName, err)
+	}
+
+	if f.ArtifactsPath != "" {
+		conf.artifactsPath = f.ArtifactsPath
+	}
+	if f.DefaultTask != "" {
+		conf.defaultTask = f.DefaultTask
+	}
+	aliases := make([]string, 0, len(f.Aliases))
+	for alias := range f.Aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		Alias(alias, f.Aliases[alias]).apply(conf)
+	}
+	conf.excludeTasks = append(conf.excludeTasks, f.ExcludeTasks...)
+	for tool, version := range f.ToolVersions {
+		ToolVersion(tool, version).apply(conf)
+	}
+	conf.buildTargets = append(conf.buildTargets, f.Targets...)
+	conf.ignorePaths = append(conf.ignorePaths, f.IgnorePaths...)
+	conf.excludeModules = append(conf.excludeModules, f.ExcludeModules...)
+	return nil
+}
@@ -0,0 +1,313 @@
+package build
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+const (
+	// CoverageReportHTML is an HTML coverage report written to coverage.html.
+	CoverageReportHTML = "html"
+	// CoverageReportFunc is a per-function coverage summary written to coverage-func.txt.
+	CoverageReportFunc = "func"
+)
+
+// coverageBlock is a single block of a Go coverage profile.
+type coverageBlock struct {
+	file  string
+	stmts int
+	count int
+}
+
+// coverageProfile is a parsed Go coverage profile, with blocks keyed by their
+// location so duplicate entries, e.g. from -coverpkg or merging profiles, are only
+// counted once.
+type coverageProfile struct {
+	mode   string
+	blocks map[string]coverageBlock
+}
+
+func readCoverageProfile(file string) (*coverageProfile, error) {
+	p := &coverageProfile{
+		blocks: map[string]coverageBlock{},
+	}
+	if err := p.read(file); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// read adds the blocks of the profile in file, summing the counts of blocks that
+// already exist.
+func (p *coverageProfile) read(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if mode, ok := strings.CutPrefix(line, "mode: "); ok {
+			if p.mode != "" && p.mode != mode {
+				return fmt.Errorf("cannot combine coverage mode %s with %s", mode, p.mode)
+			}
+			p.mode = mode
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		// Format is name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid coverage line: %q", line)
+		}
+		colon := strings.LastIndex(fields[0], ":")
+		if colon < 0 {
+			return fmt.Errorf("invalid coverage line: %q", line)
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid coverage line: %q", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return fmt.Errorf("invalid coverage line: %q", line)
+		}
+		b := p.blocks[fields[0]]
+		b.file = fields[0][:colon]
+		b.stmts = stmts
+		b.count += count
+		p.blocks[fields[0]] = b
+	}
+	return s.Err()
+}
+
+// write writes the profile to file in the format produced by go test.
+func (p *coverageProfile) write(file string) error {
+	keys := make([]string, 0, len(p.blocks))
+	for k := range p.blocks {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mode: %s\n", p.mode)
+	for _, k := range keys {
+		b := p.blocks[k]
+		count := b.count
+		if p.mode == "set" && count > 1 {
+			count = 1
+		}
+		fmt.Fprintf(&sb, "%s %d %d\n", k, b.stmts, count)
+	}
+	return os.WriteFile(file, []byte(sb.String()), 0o644)
+}
+
+// exclude removes the blocks of files whose base name matches any of the patterns.
+func (p *coverageProfile) exclude(patterns []string) {
+	for k, b := range p.blocks {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, path.Base(b.file)); ok {
+				delete(p.blocks, k)
+				break
+			}
+		}
+	}
+}
+
+// total returns the percentage of statements covered in the profile.
+func (p *coverageProfile) total() float64 {
+	var covered, total int
+	for _, b := range p.blocks {
+		total += b.stmts
+		if b.count > 0 {
+			covered += b.stmts
+		}
+	}
+	return percent(covered, total)
+}
+
+// packages returns the percentage of statements covered for each package in the profile.
+func (p *coverageProfile) packages() map[string]float64 {
+	covered := map[string]int{}
+	total := map[string]int{}
+	for _, b := range p.blocks {
+		pkg := path.Dir(b.file)
+		total[pkg] += b.stmts
+		if b.count > 0 {
+			covered[pkg] += b.stmts
+		}
+	}
+	res := make(map[string]float64, len(total))
+	for pkg, t := range total {
+		res[pkg] = percent(covered[pkg], t)
+	}
+	return res
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(covered) * 100 / float64(total)
+}
+
+// mergeModuleCoverage merges the coverage profiles of the modules into out, logging the
+// coverage of each module.
+func mergeModuleCoverage(a *goyek.A, conf *config, dirs []string, profiles []string, out string) {
+	a.Helper()
+
+	merged := &coverageProfile{
+		blocks: map[string]coverageBlock{},
+	}
+	for i, f := range profiles {
+		p, err := readCoverageProfile(f)
+		if err != nil {
+			a.Errorf("failed to read coverage profile %s: %v", f, err)
+			return
+		}
+		p.exclude(conf.coverageExclude)
+		a.Logf("coverage of module %s: %.1f%%", dirs[i], p.total())
+		if err := merged.read(f); err != nil {
+			a.Errorf("failed to merge coverage profile %s: %v", f, err)
+			return
+		}
+	}
+	if err := merged.write(out); err != nil {
+		a.Errorf("failed to write coverage profile: %v", err)
+	}
+}
+
+// excludeCoverage rewrites the coverage profile in file without the files matching
+// the configured exclude patterns, so generated code does not count towards coverage.
+func excludeCoverage(a *goyek.A, conf *config, file string) {
+	a.Helper()
+
+	if len(conf.coverageExclude) == 0 {
+		return
+	}
+
+	p, err := readCoverageProfile(file)
+	if err != nil {
+		a.Errorf("failed to read coverage profile: %v", err)
+		return
+	}
+	p.exclude(conf.coverageExclude)
+	if err := p.write(file); err != nil {
+		a.Errorf("failed to write coverage profile: %v", err)
+	}
+}
+
+// recordCoverage records the total coverage in the profile for the build report.
+func recordCoverage(a *goyek.A, file string) {
+	p, err := readCoverageProfile(file)
+	if err != nil {
+		return
+	}
+	recordOutput(a, "coverage", p.total())
+}
+
+// checkCoverage fails the task if coverage in the profile is below any configured threshold.
+func checkCoverage(a *goyek.A, conf *config, file string) {
+	a.Helper()
+
+	if conf.coverageThreshold == 0 && len(conf.packageCoverageThresholds) == 0 {
+		return
+	}
+
+	p, err := readCoverageProfile(file)
+	if err != nil {
+		a.Errorf("failed to read coverage profile: %v", err)
+		return
+	}
+
+	total := p.total()
+	a.Logf("total coverage: %.1f%%", total)
+	if total < conf.coverageThreshold {
+		a.Errorf("total coverage %.1f%% is below threshold %.1f%%", total, conf.coverageThreshold)
+	}
+
+	pkgs := p.packages()
+	names := make([]string, 0, len(conf.packageCoverageThresholds))
+	for pkg := range conf.packageCoverageThresholds {
+		names = append(names, pkg)
+	}
+	sort.Strings(names)
+	for _, pkg := range names {
+		threshold := conf.packageCoverageThresholds[pkg]
+		cov, ok := pkgs[pkg]
+		if !ok {
+			a.Errorf("no coverage found for package %s with threshold %.1f%%", pkg, threshold)
+			continue
+		}
+		if cov < threshold {
+			a.Errorf("coverage %.1f%% of package %s is below threshold %.1f%%", cov, pkg, threshold)
+		}
+	}
+}
+
+// writeCoverageReports generates the configured human-readable reports from the
+// coverage profile into the artifacts directory.
+func writeCoverageReports(a *goyek.A, conf *config, profile string) {
+	a.Helper()
+
+	for _, report := range conf.coverageReports {
+		switch report {
+		case CoverageReportHTML:
+			execCmd(a, "go tool cover "+shellJoin([]string{"-html=" + profile, "-o", filepath.Join(conf.artifactsPath, "coverage.html")}))
+		case CoverageReportFunc:
+			var out strings.Builder
+			if !execCmd(a, "go tool cover "+shellJoin([]string{"-func=" + profile}), cmd.Stdout(&out)) {
+				continue
+			}
+			if err := os.WriteFile(filepath.Join(conf.artifactsPath, "coverage-func.txt"), []byte(out.String()), 0o644); err != nil {
+				a.Errorf("failed to write function coverage report: %v", err)
+			}
+		default:
+			a.Errorf("unknown coverage report %q", report)
+		}
+	}
+}
+
+// mergeCoverage merges the coverage profiles written by test tasks into
+// coverage-merged.txt in the artifacts directory.
+func mergeCoverage(a *goyek.A, conf *config) {
+	a.Helper()
+
+	merged := &coverageProfile{
+		blocks: map[string]coverageBlock{},
+	}
+	var found bool
+	for _, run := range []testRun{{}, {name: "integration"}} {
+		file := run.artifact(conf, "coverage", ".txt")
+		if !fileExists(file) {
+			continue
+		}
+		if err := merged.read(file); err != nil {
+			a.Fatalf("failed to read coverage profile %s: %v", file, err)
+		}
+		a.Logf("merged %s", file)
+		found = true
+	}
+	if !found {
+		a.Skip("no coverage profiles found, run test tasks first")
+	}
+
+	out := filepath.Join(conf.artifactsPath, "coverage-merged.txt")
+	if err := merged.write(out); err != nil {
+		a.Fatalf("failed to write merged coverage profile: %v", err)
+	}
+	a.Logf("total merged coverage: %.1f%%", merged.total())
+}
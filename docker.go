@@ -0,0 +1,131 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+var dockerfilePatterns = []string{"Dockerfile*", "*.Dockerfile"}
+
+// dockerImage is an image to build with the docker-build task.
+type dockerImage struct {
+	name       string
+	dockerfile string
+	context    string
+}
+
+// defineDockerTasks defines tasks for Dockerfiles if the repository contains any
+// or images are configured.
+func defineDockerTasks(conf *config) {
+	if len(conf.dockerImages) > 0 {
+		goyek.Define(goyek.Task{
+			Name:  "docker-build",
+			Usage: "Builds Docker images.",
+			Action: func(a *goyek.A) {
+				dockerBuild(a, conf)
+			},
+		})
+	}
+
+	if len(findFiles(conf, dockerfilePatterns...)) == 0 {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-docker",
+		Usage: "Lints Dockerfiles.",
+		Action: func(a *goyek.A) {
+			wd, err := os.Getwd()
+			if err != nil {
+				a.Fatalf("failed to get working directory: %v", err)
+			}
+			// hadolint is not distributed as a Go program so it is run with its official image.
+			cmdLine := fmt.Sprintf("docker run --rm -v %s:/work -w /work hadolint/hadolint:%s hadolint", shellJoin([]string{wd}), conf.version("hadolint", verHadolint))
+			if conf.hadolintConfig != "" {
+				cmdLine += " --config " + shellJoin([]string{filepath.ToSlash(conf.hadolintConfig)})
+			}
+			files := findFiles(conf, dockerfilePatterns...)
+			for i, f := range files {
+				files[i] = filepath.ToSlash(f)
+			}
+			execFiles(a, cmdLine, files)
+		},
+	}))
+}
+
+func dockerBuild(a *goyek.A, conf *config) {
+	a.Helper()
+
+	outDir := filepath.Join(conf.artifactsPath, "docker")
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		a.Fatalf("failed to create docker artifacts directory: %v", err)
+	}
+
+	tags := conf.dockerTags
+	if len(tags) == 0 {
+		tags = []string{strings.ReplaceAll(gitVersion(), "+", "-"), "latest"}
+	}
+
+	var args []string
+	keys := make([]string, 0, len(conf.dockerBuildArgs))
+	for k := range conf.dockerBuildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg", k+"="+conf.dockerBuildArgs[k])
+	}
+
+	for _, img := range conf.dockerImages {
+		imgArgs := append([]string{}, args...)
+		for _, tag := range tags {
+			imgArgs = append(imgArgs, "-t", img.name+":"+tag)
+		}
+		if conf.dockerPush {
+			imgArgs = append(imgArgs, "--push")
+		} else {
+			imgArgs = append(imgArgs, "--load")
+		}
+		base := filepath.Join(outDir, strings.NewReplacer("/", "_", ":", "_").Replace(img.name))
+		imgArgs = append(imgArgs, "--metadata-file", base+".metadata.json", "-f", img.dockerfile, img.context)
+		if !execCmd(a, "docker buildx build "+shellJoin(imgArgs)) || dryRun() {
+			continue
+		}
+		// The manifest digest, which is the digest in the registry for pushed images, is
+		// written for use by later steps such as signing. The image ID from --iidfile is
+		// only the digest of the image config.
+		digest, err := dockerImageDigest(base + ".metadata.json")
+		if err != nil {
+			a.Errorf("failed to read digest of %s: %v", img.name, err)
+			continue
+		}
+		if err := os.WriteFile(base+".digest", []byte(digest), 0o644); err != nil {
+			a.Errorf("failed to write digest of %s: %v", img.name, err)
+		}
+	}
+}
+
+// dockerImageDigest returns the manifest digest of an image from the metadata file
+// written by docker buildx build.
+func dockerImageDigest(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	var metadata struct {
+		Digest string `json:"containerimage.digest"`
+	}
+	if err := json.Unmarshal(b, &metadata); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", file, err)
+	}
+	if metadata.Digest == "" {
+		return "", fmt.Errorf("no image digest in %s", file)
+	}
+	return metadata.Digest, nil
+}
@@ -0,0 +1,59 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineEditorConfigTasks defines the lint-editorconfig task if the repository has an
+// .editorconfig.
+func defineEditorConfigTasks(conf *config) {
+	if _, err := os.Stat(".editorconfig"); err != nil {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-editorconfig",
+		Usage: "Checks files follow .editorconfig, e.g. indentation, final newlines, and trailing whitespace.",
+		Action: func(a *goyek.A) {
+			ec := fmt.Sprintf("go run github.com/editorconfig-checker/editorconfig-checker/v3/cmd/editorconfig-checker@%s", conf.version("editorconfig-checker", verEditorConfig))
+			execFiles(a, ec, editorConfigFiles(a, conf))
+		},
+	}))
+}
+
+// editorConfigFiles returns the files to check, those not ignored by git so generated
+// and downloaded files are skipped, or else all files outside the artifacts directory
+// when not in a git repository. Paths configured with IgnorePaths are skipped like in
+// other tasks. Binary files are skipped by editorconfig-checker itself.
+func editorConfigFiles(a *goyek.A, conf *config) []string {
+	a.Helper()
+
+	if !inGitRepo() {
+		return globFiles(conf, "**")
+	}
+	files, err := gitFiles()
+	if err != nil {
+		a.Fatal(err)
+	}
+	var res []string
+	for _, f := range files {
+		if !ignoredPath(conf, f) && !inSkippedDir(conf, f) {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// inSkippedDir returns whether any directory containing the file is skipped by skipDir.
+func inSkippedDir(conf *config, file string) bool {
+	for dir := filepath.Dir(file); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if skipDir(conf, dir) {
+			return true
+		}
+	}
+	return false
+}
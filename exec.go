@@ -0,0 +1,171 @@
+package build
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+	"github.com/mattn/go-shellwords"
+)
+
+// execCmd runs the command the same as cmd.Exec, except with -dry-run when running
+// with Main, where the command is only logged.
+func execCmd(a *goyek.A, cmdLine string, opts ...cmd.Option) bool {
+	a.Helper()
+
+	if err := tryExec(a, cmdLine, opts...); err != nil {
+		a.Error(err)
+		return false
+	}
+	return true
+}
+
+// tryExec runs the command the same as execCmd but returns any error instead of
+// failing the task, for commands whose failure is not always fatal.
+func tryExec(a *goyek.A, cmdLine string, opts ...cmd.Option) error {
+	a.Helper()
+
+	envs, args, err := shellwords.ParseWithEnvs(cmdLine)
+	if err != nil {
+		return fmt.Errorf("parse command line: %w", err)
+	}
+	if len(args) == 0 {
+		return errors.New("empty command line")
+	}
+
+	c := exec.CommandContext(a.Context(), args[0], args[1:]...) //nolint:gosec // commands are defined by this package
+	c.Stdin = os.Stdin
+	c.Stdout = a.Output()
+	c.Stderr = a.Output()
+	environ := os.Environ()
+	c.Env = append(environ, envs...)
+	// Tools run with go run are a child of the killed go command, so don't wait for
+	// them to close output indefinitely when the task is cancelled.
+	c.WaitDelay = 10 * time.Second
+	for _, o := range opts {
+		o(a, c)
+	}
+
+	if dryRun() {
+		// Environment variables and the directory set by options are logged like a shell
+		// command line since they are not part of cmdLine.
+		line := cmdLine
+		if extra := c.Env[len(environ)+len(envs):]; len(extra) > 0 {
+			line = shellJoin(extra) + " " + line
+		}
+		if c.Dir != "" {
+			line = "cd " + shellQuote(c.Dir) + " && " + line
+		}
+		a.Log("Exec (dry run): ", line)
+		return nil
+	}
+	release, err := commands.acquire(a.Context())
+	if err != nil {
+		return err
+	}
+	defer release()
+	a.Log("Exec: ", cmdLine)
+	return c.Run()
+}
+
+// dryRun returns whether commands should only be logged instead of run. boot.Main
+// skips task actions entirely with -dry-run, so this only applies with Main, which
+// runs actions to log the commands they would run.
+func dryRun() bool {
+	f := flag.Lookup("dry-run")
+	return f != nil && f.Value.String() == "true"
+}
+
+// maxFilesArgLen is the maximum length of file arguments passed to a single command,
+// comfortably below the command line limit of Windows, the lowest of common platforms.
+const maxFilesArgLen = 24 * 1024
+
+// execFiles runs the command with the files appended as arguments, split across
+// multiple runs if needed to stay within command line length limits. All chunks are
+// run even if one fails, returning whether all succeeded.
+func execFiles(a *goyek.A, cmdLine string, files []string, opts ...cmd.Option) bool {
+	a.Helper()
+
+	// Tools generally accept forward slashes on Windows, which also keeps file lists
+	// readable in logs.
+	slashFiles := make([]string, len(files))
+	for i, f := range files {
+		slashFiles[i] = filepath.ToSlash(f)
+	}
+	ok := true
+	for _, chunk := range chunkFiles(slashFiles) {
+		if !execCmd(a, cmdLine+" "+shellJoin(chunk), opts...) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// chunkFiles splits files into groups whose quoted length is at most maxFilesArgLen.
+func chunkFiles(files []string) [][]string {
+	var chunks [][]string
+	var chunk []string
+	var n int
+	for _, f := range files {
+		l := len(shellQuote(f)) + 1
+		if len(chunk) > 0 && n+l > maxFilesArgLen {
+			chunks = append(chunks, chunk)
+			chunk, n = nil, 0
+		}
+		chunk = append(chunk, f)
+		n += l
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// execNoOutput runs the command and fails the task with the message, followed by the
+// command output, if it writes anything to stdout. This is used for tools that report
+// problems by listing them rather than with an exit code.
+func execNoOutput(a *goyek.A, cmdLine string, msg string, opts ...cmd.Option) bool {
+	a.Helper()
+
+	var out strings.Builder
+	opts = append(opts, cmd.Stdout(io.MultiWriter(a.Output(), &out)))
+	if !execCmd(a, cmdLine, opts...) {
+		return false
+	}
+	if out.Len() > 0 {
+		a.Error(msg)
+		return false
+	}
+	return true
+}
+
+// shellJoin joins args into a string that parses back into the same args with
+// cmd.Exec, for passing file paths to commands. Paths must always be quoted since
+// backslashes in Windows paths are otherwise parsed as escapes.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes arg for a POSIX shell if needed.
+func shellQuote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, needsQuote) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+func needsQuote(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%", r))
+}
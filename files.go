@@ -0,0 +1,114 @@
+package build
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// skipDir returns whether the directory should not be searched for source files,
+// e.g. when watching or finding files to lint. VCS metadata, dependency directories,
+// the artifacts directory, and any paths configured with IgnorePaths are skipped.
+func skipDir(conf *config, path string) bool {
+	path = filepath.Clean(path)
+	if path == filepath.Clean(conf.artifactsPath) {
+		return true
+	}
+	switch filepath.Base(path) {
+	case ".git", "vendor", "node_modules":
+		return true
+	}
+	return ignoredPath(conf, path)
+}
+
+// ignoredPath returns whether the path matches any of the doublestar patterns configured
+// with IgnorePaths. A pattern matching a directory ignores everything in it.
+func ignoredPath(conf *config, path string) bool {
+	slashPath := filepath.ToSlash(filepath.Clean(path))
+	for _, p := range conf.ignorePaths {
+		p = strings.TrimSuffix(p, "/")
+		if ok, _ := doublestar.Match(p, slashPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findFiles returns the paths of files under the working directory with a base name
+// matching any of the patterns, e.g. "*.proto".
+func findFiles(conf *config, patterns ...string) []string {
+	var files []string
+	_ = filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && skipDir(conf, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignoredPath(conf, path) {
+			return nil
+		}
+		for _, p := range patterns {
+			if ok, _ := filepath.Match(p, d.Name()); ok {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+// globFiles returns the paths of files under the working directory matching any of
+// the doublestar patterns, e.g. "**/*.md", expanded in Go so they behave the same on
+// any shell and platform. Patterns prefixed with "!" exclude matching files. Paths are
+// matched with forward slashes.
+func globFiles(conf *config, patterns ...string) []string {
+	var files []string
+	_ = filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && skipDir(conf, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignoredPath(conf, path) {
+			return nil
+		}
+		slashPath := filepath.ToSlash(path)
+		var matched bool
+		for _, p := range patterns {
+			if exclude, ok := strings.CutPrefix(p, "!"); ok {
+				if m, _ := doublestar.Match(exclude, slashPath); m {
+					return nil
+				}
+				continue
+			}
+			if m, _ := doublestar.Match(p, slashPath); m {
+				matched = true
+			}
+		}
+		if matched {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,21 @@
+package build
+
+import "flag"
+
+// Flags for tasks defined by this package, parsed along with goyek flags when running
+// the build, e.g. by Main.
+var (
+	affectedFlag          = flag.Bool("affected", false, "only run lint-go and Go tests for modules affected by changes since the merge base with origin/main or the ref set with AffectedModules")
+	completionCommandFlag = flag.String("completion-command", "build", "the `command` to complete with the completion task, e.g. an alias for go run ./build")
+	completionShellFlag   = flag.String("completion-shell", "", "the `shell` to print completions for with the completion task, bash, zsh, or fish, defaulting to $SHELL")
+	formatCheckFlag       = flag.Bool("check", false, "verify formatting without writing files")
+	golangciArgsFlag      = flag.String("golangci-args", "", "extra `args` for golangci-lint in the lint-go task, e.g. --build-tags=integration")
+	graphFormatFlag       = flag.String("graph-format", "dot", "the `format` of the graph task, dot or mermaid")
+	graphTaskFlag         = flag.String("graph-task", "", "only print the graph of the `task` and its dependencies with the graph task")
+	keepGoingFlag         = flag.Bool("keep-going", false, "keep running tasks after a failure, listing the failures at the end, with Main")
+	maxParallelFlag       = flag.Int("max-parallel", 0, "the maximum `number` of commands to run at once and of packages go test and golangci-lint process in parallel")
+	quietFlag             = flag.Bool("q", false, "only print failures, with Main")
+	releaseVersionFlag    = flag.String("version", "", "the `version` to tag with release-tag instead of computing it from commits")
+	testRunFlag           = flag.String("run", "", "only run tests matching the `regexp`, passed to go test -run")
+	watchTaskFlag         = flag.String("watch-task", "test", "the `task` to rerun on file changes with the watch task")
+)
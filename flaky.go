@@ -0,0 +1,143 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// flakyTest is a test that failed and then passed when retried.
+type flakyTest struct {
+	Package  string `json:"package"`
+	Test     string `json:"test"`
+	Attempts int    `json:"attempts"`
+}
+
+// retryFailedTests reruns the top-level tests that failed in events up to the
+// configured number of times, returning whether all of them eventually passed. Tests
+// that pass on retry are written to a flaky test report in the artifacts directory.
+// Packages are retried in their module directory in pkgDirs.
+func retryFailedTests(a *goyek.A, conf *config, run testRun, flags []string, events []testEvent, opts []cmd.Option, pkgDirs map[string]string) bool {
+	a.Helper()
+
+	failing := map[string]map[string]bool{}
+	for _, ev := range events {
+		if ev.Action != "fail" {
+			continue
+		}
+		if ev.Test == "" {
+			if _, ok := failing[ev.Package]; !ok {
+				failing[ev.Package] = map[string]bool{}
+			}
+			continue
+		}
+		test, _, _ := strings.Cut(ev.Test, "/")
+		if _, ok := failing[ev.Package]; !ok {
+			failing[ev.Package] = map[string]bool{}
+		}
+		failing[ev.Package][test] = true
+	}
+
+	for pkg, tests := range failing {
+		if len(tests) == 0 {
+			// The package failed without a failing test, e.g. it did not compile,
+			// which retrying will not fix.
+			a.Logf("%s failed without failing tests, not retrying", pkg)
+			return false
+		}
+	}
+
+	var flaky []flakyTest
+	for attempt := 2; attempt <= conf.testRetries+1 && len(failing) > 0; attempt++ {
+		pkgs := make([]string, 0, len(failing))
+		for pkg := range failing {
+			pkgs = append(pkgs, pkg)
+		}
+		sort.Strings(pkgs)
+		for _, pkg := range pkgs {
+			tests := failing[pkg]
+			names := make([]string, 0, len(tests))
+			for t := range tests {
+				names = append(names, regexp.QuoteMeta(t))
+			}
+			sort.Strings(names)
+			a.Logf("retrying failed tests in %s, attempt %d", pkg, attempt)
+
+			w := &testEventWriter{out: a.Output()}
+			// -run comes after flags so it overrides a -run in them.
+			retryFlags := append(append([]string{"-json"}, flags...), "-count=1", "-run=^("+strings.Join(names, "|")+")$")
+			if err := tryExec(a, fmt.Sprintf("go test %s %s", shellJoin(retryFlags), pkg), append(moduleOpts(pkgDirs[pkg], opts), cmd.Stdout(w))...); err != nil {
+				a.Logf("retry failed: %v", err)
+			}
+			w.Flush()
+
+			for _, ev := range w.Events() {
+				if ev.Test == "" || strings.Contains(ev.Test, "/") || !tests[ev.Test] {
+					continue
+				}
+				if ev.Action == "pass" {
+					flaky = append(flaky, flakyTest{Package: pkg, Test: ev.Test, Attempts: attempt})
+					delete(tests, ev.Test)
+				}
+			}
+			if len(tests) == 0 {
+				delete(failing, pkg)
+			}
+		}
+	}
+
+	if err := writeFlakyReport(conf, run, flaky); err != nil {
+		a.Errorf("failed to write flaky test report: %v", err)
+	}
+	for _, f := range flaky {
+		a.Logf("WARNING: %s %s is flaky, passed on attempt %d", f.Package, f.Test, f.Attempts)
+	}
+
+	if len(failing) > 0 {
+		return false
+	}
+	if conf.flakyTestBudget >= 0 && len(flaky) > conf.flakyTestBudget {
+		a.Logf("%d flaky tests exceeds budget of %d", len(flaky), conf.flakyTestBudget)
+		return false
+	}
+	return true
+}
+
+// writeFlakyReport writes the flaky tests as JSON and Markdown to the artifacts directory.
+func writeFlakyReport(conf *config, run testRun, flaky []flakyTest) error {
+	sort.Slice(flaky, func(i, j int) bool {
+		if flaky[i].Package != flaky[j].Package {
+			return flaky[i].Package < flaky[j].Package
+		}
+		return flaky[i].Test < flaky[j].Test
+	})
+	if flaky == nil {
+		flaky = []flakyTest{}
+	}
+
+	b, err := json.MarshalIndent(flaky, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(run.artifact(conf, "flaky-tests", ".json"), b, 0o644); err != nil {
+		return err
+	}
+
+	var md strings.Builder
+	md.WriteString("# Flaky tests\n\n")
+	if len(flaky) == 0 {
+		md.WriteString("No flaky tests detected.\n")
+	} else {
+		md.WriteString("| Package | Test | Passed on attempt |\n| --- | --- | --- |\n")
+		for _, f := range flaky {
+			fmt.Fprintf(&md, "| %s | %s | %d |\n", f.Package, f.Test, f.Attempts)
+		}
+	}
+	return os.WriteFile(run.artifact(conf, "flaky-tests", ".md"), []byte(md.String()), 0o644)
+}
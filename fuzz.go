@@ -0,0 +1,151 @@
+package build
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// fuzzTarget is a fuzz test in a package.
+type fuzzTarget struct {
+	pkg  string
+	dir  string
+	name string
+}
+
+// runFuzz runs each fuzz test for the configured duration, copying the generated
+// corpus and any new crashing inputs into the fuzz artifacts directory.
+func runFuzz(a *goyek.A, conf *config) {
+	a.Helper()
+
+	targets, err := findFuzzTargets(a)
+	if err != nil {
+		a.Fatalf("failed to find fuzz tests: %v", err)
+	}
+	if len(targets) == 0 {
+		a.Skip("no fuzz tests found")
+	}
+
+	cacheOut, err := exec.Command("go", "env", "GOCACHE").Output()
+	if err != nil {
+		a.Fatalf("failed to find go build cache: %v", err)
+	}
+	goCache := strings.TrimSpace(string(cacheOut))
+
+	fuzzTime := conf.fuzzTime
+	if fuzzTime == 0 {
+		fuzzTime = 10 * time.Second
+	}
+
+	outDir := filepath.Join(conf.artifactsPath, "fuzz")
+	for _, t := range targets {
+		// go test writes crashing inputs to testdata/fuzz in the package.
+		crashDir := filepath.Join(t.dir, "testdata", "fuzz", t.name)
+		before := listFiles(crashDir)
+
+		cmdLine := fmt.Sprintf("go test -run=^$ -fuzz=^%s$ -fuzztime=%s %s", t.name, fuzzTime, t.pkg)
+		runErr := tryExec(a, cmdLine)
+
+		corpus := filepath.Join(goCache, "fuzz", t.pkg, t.name)
+		if err := copyDir(filepath.Join(outDir, "corpus", t.pkg, t.name), corpus, nil); err != nil {
+			a.Errorf("failed to copy corpus of %s: %v", t.name, err)
+		}
+		if err := copyDir(filepath.Join(outDir, "crashers", t.pkg, t.name), crashDir, before); err != nil {
+			a.Errorf("failed to copy crashers of %s: %v", t.name, err)
+		}
+
+		if runErr == nil {
+			continue
+		}
+		if conf.fuzzFailOnCrash {
+			a.Errorf("%s in %s failed: %v", t.name, t.pkg, runErr)
+		} else {
+			a.Logf("WARNING: %s in %s failed, crashing inputs are in %s: %v", t.name, t.pkg, crashDir, runErr)
+		}
+	}
+}
+
+// findFuzzTargets lists the fuzz tests of all packages.
+func findFuzzTargets(a *goyek.A) ([]fuzzTarget, error) {
+	a.Helper()
+
+	var dirs strings.Builder
+	if !execCmd(a, "go list -f {{.ImportPath}}={{.Dir}} ./...", cmd.Stdout(&dirs)) {
+		return nil, fmt.Errorf("go list failed")
+	}
+	pkgDirs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(dirs.String()), "\n") {
+		if pkg, dir, ok := strings.Cut(line, "="); ok {
+			pkgDirs[pkg] = dir
+		}
+	}
+
+	// go test -list prints matching test names followed by a line with the result and
+	// package of each package.
+	var list strings.Builder
+	if !execCmd(a, "go test -list ^Fuzz ./...", cmd.Stdout(&list)) {
+		return nil, fmt.Errorf("go test -list failed")
+	}
+	var targets []fuzzTarget
+	var names []string
+	s := bufio.NewScanner(strings.NewReader(list.String()))
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, "Fuzz") {
+			names = append(names, line)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "ok" {
+			for _, name := range names {
+				targets = append(targets, fuzzTarget{pkg: fields[1], dir: pkgDirs[fields[1]], name: name})
+			}
+		}
+		names = nil
+	}
+	return targets, nil
+}
+
+// listFiles returns the names of files in dir, or nil if it does not exist.
+func listFiles(dir string) map[string]bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	files := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		files[e.Name()] = true
+	}
+	return files
+}
+
+// copyDir copies the files in src, except those in skip, into dst. Nothing is copied
+// if src does not exist.
+func copyDir(dst string, src string, skip map[string]bool) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || skip[e.Name()] {
+			continue
+		}
+		if err := os.MkdirAll(dst, 0o755); err != nil {
+			return err
+		}
+		if err := copyToFile(filepath.Join(dst, e.Name()), filepath.Join(src, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
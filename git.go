@@ -0,0 +1,150 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func inGitRepo() bool {
+	return exec.Command("git", "rev-parse", "--is-inside-work-tree").Run() == nil
+}
+
+// gitVersion returns a version for the current commit based on git tags, or "dev"
+// if it cannot be determined.
+func gitVersion() string {
+	out, err := exec.Command("git", "describe", "--tags", "--always", "--dirty").Output()
+	if err != nil {
+		return "dev"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitCommit returns the full SHA of the current commit.
+func gitCommit() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("finding commit: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitCommitTime returns the committer time of the current commit, or of the last
+// commit changing any of paths if provided.
+func gitCommitTime(paths ...string) (time.Time, error) {
+	args := []string{"log", "-1", "--format=%ct"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("finding commit time: %w", err)
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing commit time: %w", err)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// gitRefExists returns whether the ref, e.g. a branch or tag, names a commit.
+func gitRefExists(ref string) bool {
+	return exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run() == nil
+}
+
+// gitMergeBase returns the commit HEAD branched from ref.
+func gitMergeBase(ref string) (string, error) {
+	out, err := exec.Command("git", "merge-base", "HEAD", ref).Output()
+	if err != nil {
+		return "", fmt.Errorf("finding merge base with %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitBranch returns the name of the current branch, preferring the branch reported by
+// CI since checkouts in CI are often detached.
+func gitBranch() string {
+	if b := os.Getenv("GITHUB_HEAD_REF"); b != "" {
+		return b
+	}
+	if b := os.Getenv("GITHUB_REF_NAME"); b != "" {
+		return b
+	}
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitChangedFiles returns the files changed in the working tree since the commit,
+// relative to the working directory.
+func gitChangedFiles(commit string) ([]string, error) {
+	out, err := exec.Command("git", "diff", "--name-only", "--relative", "-z", commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("finding changed files: %w", err)
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// gitFiles returns the files in the working tree not ignored by git, both tracked and
+// untracked, relative to the working directory. Deleted files are not included.
+func gitFiles() ([]string, error) {
+	out, err := exec.Command("git", "ls-files", "-z", "--cached", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing files: %w", err)
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" && fileExists(f) {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// gitDirtyFiles returns the files with changes in the working tree or index, including
+// untracked files, mapped to a hash of their contents, empty if deleted. Paths are
+// relative to the root of the repository.
+func gitDirtyFiles() (map[string]string, error) {
+	root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("finding repository root: %w", err)
+	}
+	out, err := exec.Command("git", "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing changed files: %w", err)
+	}
+	files := map[string]string{}
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			continue
+		}
+		// Renames and copies are followed by the original path.
+		if e[0] == 'R' || e[0] == 'C' {
+			i++
+		}
+		path := e[3:]
+		var hash string
+		if b, err := os.ReadFile(filepath.Join(strings.TrimSpace(string(root)), path)); err == nil {
+			sum := sha256.Sum256(b)
+			hash = hex.EncodeToString(sum[:])
+		}
+		files[path] = hash
+	}
+	return files, nil
+}
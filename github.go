@@ -0,0 +1,94 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// inGitHubActions returns whether the build is running in GitHub Actions with a step
+// summary available.
+func inGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true" && os.Getenv("GITHUB_STEP_SUMMARY") != ""
+}
+
+// writeStepSummary appends Markdown to the GitHub Actions step summary.
+func writeStepSummary(a *goyek.A, markdown string) {
+	a.Helper()
+
+	f, err := os.OpenFile(os.Getenv("GITHUB_STEP_SUMMARY"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		a.Logf("failed to open step summary: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(markdown + "\n"); err != nil {
+		a.Logf("failed to write step summary: %v", err)
+	}
+}
+
+// testStepSummary returns a Markdown summary of a test run with counts of results,
+// failed tests, coverage if the profile exists, and the slowest tests.
+func testStepSummary(title string, events []testEvent, coverageFile string) string {
+	type result struct {
+		pkg     string
+		test    string
+		elapsed float64
+	}
+
+	var passed, skipped int
+	var failed, tests []result
+	for _, ev := range events {
+		if ev.Test == "" {
+			continue
+		}
+		switch ev.Action {
+		case "pass":
+			passed++
+		case "skip":
+			skipped++
+		case "fail":
+			failed = append(failed, result{ev.Package, ev.Test, ev.Elapsed})
+		default:
+			continue
+		}
+		if ev.Action != "skip" {
+			tests = append(tests, result{ev.Package, ev.Test, ev.Elapsed})
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s\n\n", title)
+	status := ":white_check_mark:"
+	if len(failed) > 0 {
+		status = ":x:"
+	}
+	fmt.Fprintf(&sb, "%s %d passed, %d failed, %d skipped\n", status, passed, len(failed), skipped)
+	if p, err := readCoverageProfile(coverageFile); err == nil {
+		fmt.Fprintf(&sb, "\nCoverage: %.1f%%\n", p.total())
+	}
+
+	if len(failed) > 0 {
+		sb.WriteString("\n#### Failures\n\n")
+		for _, r := range failed {
+			fmt.Fprintf(&sb, "- `%s` %s\n", r.pkg, r.test)
+		}
+	}
+
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].elapsed > tests[j].elapsed
+	})
+	if len(tests) > numSlowestTests {
+		tests = tests[:numSlowestTests]
+	}
+	if len(tests) > 0 {
+		sb.WriteString("\n#### Slowest tests\n\n| Test | Package | Duration |\n| --- | --- | --- |\n")
+		for _, r := range tests {
+			fmt.Fprintf(&sb, "| %s | `%s` | %.2fs |\n", r.test, r.pkg, r.elapsed)
+		}
+	}
+	return sb.String()
+}
@@ -3,8 +3,19 @@ module github.com/curioswitch/go-build
 go 1.20
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.6.1
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/goyek/goyek/v2 v2.1.0
 	github.com/goyek/x v0.1.7
+	github.com/mattn/go-shellwords v1.0.12
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	golang.org/x/mod v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/mattn/go-shellwords v1.0.12 // indirect
+require (
+	github.com/fatih/color v1.16.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/sys v0.14.0 // indirect
+)
@@ -0,0 +1,123 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// checkModTidy fails the task if running go mod tidy would change go.mod or go.sum
+// of the module in dir. Tidy is run with -modfile on copies of the files so the
+// module's own files are never modified.
+func checkModTidy(a *goyek.A, dir string) {
+	a.Helper()
+
+	tmp, err := os.MkdirTemp("", "go-build-tidy")
+	if err != nil {
+		a.Errorf("failed to create temp directory: %v", err)
+		return
+	}
+	defer os.RemoveAll(tmp)
+
+	// The go.sum used with -modfile is the one next to it.
+	files := []string{"go.mod", "go.sum"}
+	orig := make([][]byte, len(files))
+	for i, f := range files {
+		b, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			a.Errorf("failed to read %s: %v", filepath.Join(dir, f), err)
+			return
+		}
+		orig[i] = b
+		if b == nil {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(tmp, f), b, 0o644); err != nil {
+			a.Errorf("failed to copy %s: %v", filepath.Join(dir, f), err)
+			return
+		}
+	}
+
+	if !execCmd(a, "go mod tidy -modfile="+shellQuote(filepath.ToSlash(filepath.Join(tmp, "go.mod"))), cmd.Dir(dir)) || dryRun() {
+		return
+	}
+
+	for i, f := range files {
+		b, err := os.ReadFile(filepath.Join(tmp, f))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			a.Errorf("failed to read tidied %s: %v", f, err)
+			continue
+		}
+		if !bytes.Equal(b, orig[i]) {
+			a.Errorf("%s is not tidy, run go mod tidy in %s", filepath.Join(dir, f), dir)
+		}
+	}
+}
+
+// checkUnchanged fails the task if running the command in dir changes any of the files.
+// The command is run in a copy of the working directory, skipping the same paths as
+// other tasks, so the files are never modified.
+func checkUnchanged(a *goyek.A, conf *config, files []string, cmdLine string, dir string) {
+	a.Helper()
+
+	if dryRun() {
+		execCmd(a, cmdLine, cmd.Dir(dir))
+		return
+	}
+
+	tmp, err := os.MkdirTemp("", "go-build-check")
+	if err != nil {
+		a.Errorf("failed to create temp directory: %v", err)
+		return
+	}
+	defer os.RemoveAll(tmp)
+	if err := copyTree(conf, tmp); err != nil {
+		a.Errorf("failed to copy working directory: %v", err)
+		return
+	}
+
+	if !execCmd(a, cmdLine, cmd.Dir(filepath.Join(tmp, dir))) {
+		return
+	}
+
+	for _, f := range files {
+		orig, err := os.ReadFile(f)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			a.Errorf("failed to read %s: %v", f, err)
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(tmp, f))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			a.Errorf("failed to read %s: %v", f, err)
+			continue
+		}
+		if !bytes.Equal(b, orig) {
+			a.Errorf("%s is not up to date, run %s in %s", f, cmdLine, dir)
+		}
+	}
+}
+
+// copyTree copies the files in the working directory to dst, skipping directories
+// skipped by skipDir. Symlinks are not followed.
+func copyTree(conf *config, dst string) error {
+	return filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != "." && skipDir(conf, path) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, path), 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyToFile(filepath.Join(dst, path), path)
+	})
+}
@@ -0,0 +1,62 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// defineSecurityTask defines the lint-security task if enabled.
+func defineSecurityTask(conf *config) {
+	if !conf.gosec {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-security",
+		Usage: "Scans Go code for security problems with gosec.",
+		Action: func(a *goyek.A) {
+			lintSecurity(a, conf)
+		},
+	}))
+}
+
+// lintSecurity runs gosec in each Go module, writing a report for each into the
+// artifacts directory, as SARIF if LintSARIF is enabled and otherwise as JSON.
+func lintSecurity(a *goyek.A, conf *config) {
+	a.Helper()
+
+	dirs, err := goModules(conf)
+	if err != nil {
+		a.Fatalf("failed to find Go modules: %v", err)
+	}
+
+	format, reportDir := "json", filepath.Join(conf.artifactsPath, "security")
+	if conf.lintSARIF {
+		format, reportDir = "sarif", conf.sarifPath()
+	}
+	reportDir, err = filepath.Abs(reportDir)
+	if err != nil {
+		a.Fatalf("failed to resolve report directory: %v", err)
+	}
+	if err := os.MkdirAll(reportDir, 0o755); err != nil {
+		a.Fatalf("failed to create report directory: %v", err)
+	}
+
+	for _, dir := range dirs {
+		name := "gosec"
+		if dir != "." {
+			name += "-" + strings.ReplaceAll(filepath.ToSlash(dir), "/", "-")
+		}
+		report := filepath.Join(reportDir, name+"."+format)
+		cmdLine := fmt.Sprintf("go run github.com/securego/gosec/v2/cmd/gosec@%s -fmt=%s -out=%s -stdout -verbose=text", conf.version("gosec", verGosec), format, shellJoin([]string{report}))
+		if conf.gosecSeverity != "" {
+			cmdLine += " -severity=" + conf.gosecSeverity
+		}
+		execCmd(a, cmdLine+" ./...", cmd.Dir(dir))
+	}
+}
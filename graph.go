@@ -0,0 +1,106 @@
+package build
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+const (
+	graphFormatDOT     = "dot"
+	graphFormatMermaid = "mermaid"
+)
+
+// printGraph prints the dependency graph of the defined tasks, or only of root and
+// its dependencies if set, in the requested format.
+func printGraph(a *goyek.A, format string, root string) {
+	a.Helper()
+
+	tasks := map[string]*goyek.DefinedTask{}
+	for _, t := range goyek.Tasks() {
+		tasks[t.Name()] = t
+	}
+
+	var names []string
+	if root == "" {
+		for name := range tasks {
+			names = append(names, name)
+		}
+	} else {
+		t, ok := tasks[root]
+		if !ok {
+			a.Fatalf("task %q is not defined", root)
+		}
+		seen := map[string]bool{}
+		var visit func(t *goyek.DefinedTask)
+		visit = func(t *goyek.DefinedTask) {
+			if seen[t.Name()] {
+				return
+			}
+			seen[t.Name()] = true
+			names = append(names, t.Name())
+			for _, dep := range t.Deps() {
+				visit(dep)
+			}
+		}
+		visit(t)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	switch format {
+	case graphFormatDOT:
+		sb.WriteString("digraph tasks {\n\trankdir=LR;\n")
+		for _, name := range names {
+			fmt.Fprintf(&sb, "\t%q;\n", name)
+			for _, dep := range depNames(tasks[name]) {
+				fmt.Fprintf(&sb, "\t%q -> %q;\n", name, dep)
+			}
+		}
+		sb.WriteString("}\n")
+	case graphFormatMermaid:
+		sb.WriteString("graph LR\n")
+		for _, name := range names {
+			deps := depNames(tasks[name])
+			if len(deps) == 0 {
+				fmt.Fprintf(&sb, "    %s\n", mermaidNode(name))
+				continue
+			}
+			for _, dep := range deps {
+				fmt.Fprintf(&sb, "    %s --> %s\n", mermaidNode(name), mermaidNode(dep))
+			}
+		}
+	default:
+		a.Fatalf("unknown graph format %q, must be %s or %s", format, graphFormatDOT, graphFormatMermaid)
+	}
+	// Written directly to the flow output so the graph is printed without -v.
+	fmt.Fprint(goyek.Output(), sb.String())
+}
+
+func depNames(t *goyek.DefinedTask) []string {
+	deps := make([]string, 0, len(t.Deps()))
+	for _, dep := range t.Deps() {
+		deps = append(deps, dep.Name())
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// mermaidNode returns a node for the task, with an ID safe for Mermaid since task
+// names may contain characters like dashes and colons.
+func mermaidNode(name string) string {
+	return fmt.Sprintf("%s[%q]", identifier(name), name)
+}
+
+// identifier returns s with any characters other than ASCII letters and digits
+// replaced with underscores.
+func identifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,130 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// hookMarker identifies hooks installed by install-hooks, so other hooks are not
+// overwritten or removed.
+const hookMarker = "# Installed by go-build install-hooks."
+
+// defineHookTasks defines the install-hooks and uninstall-hooks tasks when in a git
+// repository.
+func defineHookTasks(conf *config) {
+	if !inGitRepo() {
+		return
+	}
+
+	goyek.Define(goyek.Task{
+		Name:  "install-hooks",
+		Usage: "Installs git hooks running build tasks before commit and push.",
+		Action: func(a *goyek.A) {
+			installHooks(a, conf)
+		},
+	})
+
+	goyek.Define(goyek.Task{
+		Name:  "uninstall-hooks",
+		Usage: "Removes git hooks installed by install-hooks.",
+		Action: func(a *goyek.A) {
+			uninstallHooks(a)
+		},
+	})
+}
+
+func (c *config) hooks() map[string][]string {
+	if len(c.gitHooks) > 0 {
+		return c.gitHooks
+	}
+	return map[string][]string{
+		"pre-commit": {"-check", "format"},
+		"pre-push":   {"lint"},
+	}
+}
+
+func installHooks(a *goyek.A, conf *config) {
+	a.Helper()
+
+	dir, err := gitHooksDir()
+	if err != nil {
+		a.Fatal(err)
+	}
+	if !dryRun() {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			a.Fatalf("failed to create hooks directory: %v", err)
+		}
+	}
+
+	hooks := conf.hooks()
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if content, err := os.ReadFile(path); err == nil && !strings.Contains(string(content), hookMarker) {
+			a.Errorf("not overwriting existing %s hook not installed by go-build", name)
+			continue
+		}
+		script := fmt.Sprintf("#!/bin/sh\n%s\nexec go run %s\n", hookMarker, shellJoin(append([]string{conf.buildPkg()}, hooks[name]...)))
+		if dryRun() {
+			a.Log("Write (dry run): ", path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+			a.Errorf("failed to write %s hook: %v", name, err)
+			continue
+		}
+		a.Logf("installed %s hook", name)
+	}
+}
+
+func uninstallHooks(a *goyek.A) {
+	a.Helper()
+
+	dir, err := gitHooksDir()
+	if err != nil {
+		a.Fatal(err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return
+		}
+		a.Fatalf("failed to read hooks directory: %v", err)
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.Name())
+		content, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(content), hookMarker) {
+			continue
+		}
+		if dryRun() {
+			a.Log("Delete (dry run): ", path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			a.Errorf("failed to remove %s hook: %v", f.Name(), err)
+			continue
+		}
+		a.Logf("removed %s hook", f.Name())
+	}
+}
+
+// gitHooksDir returns the directory git runs hooks from, respecting core.hooksPath
+// and worktrees.
+func gitHooksDir() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("finding git hooks directory: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
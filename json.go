@@ -0,0 +1,157 @@
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/goyek/goyek/v2"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	// Allows schemas to be referenced by URL.
+	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader"
+)
+
+var defaultJSONPatterns = []string{
+	"**/*.json",
+	"**/*.json5",
+	// Generated by package managers.
+	"!**/package-lock.json",
+	// Test inputs may be invalid on purpose.
+	"!**/testdata/**",
+}
+
+// jsoncPatterns match files commonly written as JSON with comments, which are
+// formatted but not parsed as JSON.
+var jsoncPatterns = []string{
+	"**/*.json5",
+	"**/tsconfig*.json",
+	".vscode/**",
+	".devcontainer/**",
+}
+
+// jsonSchema is a JSON schema to validate files matching a pattern with.
+type jsonSchema struct {
+	pattern string
+	schema  string
+}
+
+// defineJSONTasks defines tasks for JSON files if enabled with JSONTasks and the
+// repository contains any.
+func defineJSONTasks(conf *config) {
+	if !conf.jsonTasks || len(globFiles(conf, defaultJSONPatterns...)) == 0 {
+		return
+	}
+
+	RegisterFormatTask(goyek.Define(goyek.Task{
+		Name:  "format-json",
+		Usage: "Formats JSON files with prettier.",
+		Action: func(a *goyek.A) {
+			runPrettier(a, conf, globFiles(conf, defaultJSONPatterns...))
+		},
+	}))
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-json",
+		Usage: "Checks JSON files are valid, and match any schemas configured with JSONSchema.",
+		Action: func(a *goyek.A) {
+			lintJSON(a, conf, globFiles(conf, defaultJSONPatterns...))
+		},
+	}))
+}
+
+func lintJSON(a *goyek.A, conf *config, files []string) {
+	a.Helper()
+
+	schemas := make([]*jsonschema.Schema, len(conf.jsonSchemas))
+	for i, s := range conf.jsonSchemas {
+		schema, err := jsonschema.Compile(s.schema)
+		if err != nil {
+			a.Errorf("failed to compile JSON schema %s: %v", s.schema, err)
+			return
+		}
+		schemas[i] = schema
+	}
+
+	for _, f := range files {
+		slashPath := filepath.ToSlash(f)
+		if matchAny(jsoncPatterns, slashPath) {
+			continue
+		}
+		b, err := os.ReadFile(f)
+		if err != nil {
+			a.Errorf("failed to read %s: %v", f, err)
+			continue
+		}
+		v, err := decodeJSON(f, b)
+		if err != nil {
+			a.Error(err)
+			continue
+		}
+		for i, s := range conf.jsonSchemas {
+			if ok, _ := doublestar.Match(s.pattern, slashPath); !ok {
+				continue
+			}
+			validateSchema(a, schemas[i], s.schema, f, v)
+		}
+	}
+}
+
+// decodeJSON parses the contents of the JSON file, which must be a single value, with
+// numbers kept as json.Number for schema validation.
+func decodeJSON(file string, b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%s: %w", jsonErrorPosition(file, b, err), err)
+	}
+	end := dec.InputOffset()
+	if err := dec.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			line := bytes.Count(b[:end], []byte("\n")) + 1
+			return nil, fmt.Errorf("%s:%d: unexpected data after top-level value", file, line)
+		}
+		return nil, fmt.Errorf("%s: %w", jsonErrorPosition(file, b, err), err)
+	}
+	return v, nil
+}
+
+// validateSchema validates the parsed value of the file against the schema loaded from
+// url, failing the task with all validation errors if it does not match.
+func validateSchema(a *goyek.A, schema *jsonschema.Schema, url string, file string, v any) {
+	a.Helper()
+
+	if err := schema.Validate(v); err != nil {
+		var verr *jsonschema.ValidationError
+		if errors.As(err, &verr) {
+			a.Errorf("%s does not match schema %s:\n%s", file, url, strings.TrimSpace(fmt.Sprintf("%#v", verr)))
+			return
+		}
+		a.Errorf("%s does not match schema %s: %v", file, url, err)
+	}
+}
+
+// jsonErrorPosition returns the file and line of a JSON syntax error.
+func jsonErrorPosition(file string, b []byte, err error) string {
+	var serr *json.SyntaxError
+	if !errors.As(err, &serr) {
+		return file
+	}
+	line := bytes.Count(b[:serr.Offset], []byte("\n")) + 1
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+func matchAny(patterns []string, slashPath string) bool {
+	for _, p := range patterns {
+		if ok, _ := doublestar.Match(p, slashPath); ok {
+			return true
+		}
+	}
+	return false
+}
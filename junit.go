@@ -0,0 +1,127 @@
+package build
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// writeJUnitReport converts go test -json events into a JUnit XML report, with one
+// test suite per package.
+func writeJUnitReport(file string, events []testEvent) error {
+	type testKey struct {
+		pkg  string
+		test string
+	}
+
+	suites := map[string]*junitTestSuite{}
+	cases := map[testKey]*junitTestCase{}
+	output := map[testKey]*strings.Builder{}
+	var order []testKey
+
+	for _, ev := range events {
+		suite, ok := suites[ev.Package]
+		if !ok {
+			suite = &junitTestSuite{Name: ev.Package}
+			suites[ev.Package] = suite
+		}
+		if ev.Test == "" {
+			if ev.Action == "pass" || ev.Action == "fail" || ev.Action == "skip" {
+				suite.Time = formatSeconds(ev.Elapsed)
+			}
+			continue
+		}
+
+		key := testKey{ev.Package, ev.Test}
+		switch ev.Action {
+		case "run":
+			if _, ok := cases[key]; !ok {
+				cases[key] = &junitTestCase{Name: ev.Test, ClassName: ev.Package}
+				output[key] = &strings.Builder{}
+				order = append(order, key)
+			}
+		case "output":
+			if sb, ok := output[key]; ok {
+				sb.WriteString(ev.Output)
+			}
+		case "pass", "fail", "skip":
+			tc, ok := cases[key]
+			if !ok {
+				tc = &junitTestCase{Name: ev.Test, ClassName: ev.Package}
+				cases[key] = tc
+				output[key] = &strings.Builder{}
+				order = append(order, key)
+			}
+			tc.Time = formatSeconds(ev.Elapsed)
+			switch ev.Action {
+			case "fail":
+				tc.Failure = &junitMessage{Message: "Failed", Contents: output[key].String()}
+			case "skip":
+				tc.Skipped = &junitMessage{Message: "Skipped", Contents: output[key].String()}
+			}
+		}
+	}
+
+	for _, key := range order {
+		tc := cases[key]
+		suite := suites[key.pkg]
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, *tc)
+	}
+
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var report junitTestSuites
+	for _, name := range names {
+		report.Suites = append(report.Suites, *suites[name])
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append([]byte(xml.Header), append(out, '\n')...), 0o644)
+}
+
+func formatSeconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
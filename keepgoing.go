@@ -0,0 +1,97 @@
+package build
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// keepGoing is set by Main when tasks should keep running after a failure.
+var keepGoing bool
+
+// continueOnFailure returns a middleware reporting failed tasks as passed to the flow
+// so it keeps running other tasks. It must be used after status reporting middlewares
+// so failures are still reported.
+func continueOnFailure(next goyek.Runner) goyek.Runner {
+	return func(in goyek.Input) goyek.Result {
+		res := next(in)
+		if res.Status == goyek.StatusFailed {
+			res.Status = goyek.StatusPassed
+		}
+		return res
+	}
+}
+
+// skipFailedDeps returns a middleware skipping tasks when keeping going if any of their
+// dependencies failed.
+func skipFailedDeps(next goyek.Runner) goyek.Runner {
+	return func(in goyek.Input) goyek.Result {
+		if !keepGoing {
+			return next(in)
+		}
+		if dep := failedDep(in.TaskName); dep != "" {
+			fmt.Fprintf(in.Output, "skipping %s since %s failed\n", in.TaskName, dep)
+			return goyek.Result{Status: goyek.StatusSkipped}
+		}
+		return next(in)
+	}
+}
+
+// failedDep returns the name of a failed dependency of the task, directly or through
+// other dependencies, or an empty string if there is none. Aggregate tasks without an
+// action are never skipped.
+func failedDep(name string) string {
+	t := findTask(name)
+	if t == nil || t.Action() == nil {
+		return ""
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	status := make(map[string]string, len(recorder.tasks))
+	for _, r := range recorder.tasks {
+		status[r.Name] = r.Status
+	}
+
+	seen := map[string]bool{}
+	var find func(t *goyek.DefinedTask) string
+	find = func(t *goyek.DefinedTask) string {
+		for _, dep := range t.Deps() {
+			if seen[dep.Name()] {
+				continue
+			}
+			seen[dep.Name()] = true
+			if status[dep.Name()] == "fail" {
+				return dep.Name()
+			}
+			if n := find(dep); n != "" {
+				return n
+			}
+		}
+		return ""
+	}
+	return find(t)
+}
+
+// printFailures prints the tasks that failed while keeping going, returning whether
+// there were any.
+func printFailures(out io.Writer) bool {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	var failed []string
+	for _, r := range recorder.tasks {
+		if r.Status == "fail" {
+			failed = append(failed, r.Name)
+		}
+	}
+	if len(failed) == 0 {
+		return false
+	}
+	fmt.Fprintf(out, "\n=== Failed tasks (%d)\n", len(failed))
+	for _, name := range failed {
+		fmt.Fprintf(out, "--- FAIL: %s\n", name)
+	}
+	return true
+}
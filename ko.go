@@ -0,0 +1,52 @@
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// defineKoTasks defines the docker-ko task if a repository for ko images is configured.
+func defineKoTasks(conf *config) {
+	if conf.koRepository == "" || !dirExists("cmd") {
+		return
+	}
+
+	goyek.Define(goyek.Task{
+		Name:  "docker-ko",
+		Usage: "Builds container images for binaries under ./cmd with ko.",
+		Action: func(a *goyek.A) {
+			outDir := filepath.Join(conf.artifactsPath, "docker")
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				a.Fatalf("failed to create docker artifacts directory: %v", err)
+			}
+
+			cmdLine := fmt.Sprintf("go run github.com/google/ko@%s build --base-import-paths", conf.version("ko", verKo))
+			if !conf.koPush {
+				// Without pushing, images are loaded into the local Docker daemon.
+				cmdLine += " --local"
+			}
+			cmdLine += " ./cmd/..."
+
+			opts := []cmd.Option{cmd.Env("KO_DOCKER_REPO", conf.koRepository)}
+			if conf.koBaseImage != "" {
+				opts = append(opts, cmd.Env("KO_DEFAULTBASEIMAGE", conf.koBaseImage))
+			}
+
+			// ko writes the references of built images to stdout.
+			var refs strings.Builder
+			opts = append(opts, cmd.Stdout(io.MultiWriter(a.Output(), &refs)))
+			if !execCmd(a, cmdLine, opts...) {
+				return
+			}
+			if err := os.WriteFile(filepath.Join(outDir, "ko-images.txt"), []byte(refs.String()), 0o644); err != nil {
+				a.Errorf("failed to write image references: %v", err)
+			}
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package build
+
+import (
+	"fmt"
+
+	"github.com/goyek/goyek/v2"
+)
+
+var defaultLicenseHeaderPatterns = []string{"*.go", "*.proto", "*.sh"}
+
+// defineLicenseHeaderTasks defines tasks for license headers if a header template
+// is configured.
+func defineLicenseHeaderTasks(conf *config) {
+	if conf.licenseHeaderTemplate == "" {
+		return
+	}
+
+	RegisterFormatTask(goyek.Define(goyek.Task{
+		Name:  "format-license-header",
+		Usage: "Adds missing license headers to files.",
+		Action: func(a *goyek.A) {
+			if conf.formatCheckOnly() {
+				addLicense(a, conf, "-check")
+				return
+			}
+			addLicense(a, conf, "")
+		},
+	}))
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-license-header",
+		Usage: "Checks files have license headers.",
+		Action: func(a *goyek.A) {
+			addLicense(a, conf, "-check")
+		},
+	}))
+}
+
+func addLicense(a *goyek.A, conf *config, flags string) {
+	a.Helper()
+
+	patterns := conf.licenseHeaderPatterns
+	if len(patterns) == 0 {
+		patterns = defaultLicenseHeaderPatterns
+	}
+	files := findFiles(conf, patterns...)
+	if len(files) == 0 {
+		return
+	}
+	cmdLine := fmt.Sprintf("go run github.com/google/addlicense@%s -f %s", conf.version("addlicense", verAddLicense), shellJoin([]string{conf.licenseHeaderTemplate}))
+	if flags != "" {
+		cmdLine += " " + flags
+	}
+	execCmd(a, cmdLine+" "+shellJoin(files))
+}
@@ -0,0 +1,79 @@
+package build
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// defineLicensesTask defines the lint-licenses task if allowed or denied licenses are
+// configured.
+func defineLicensesTask(conf *config) {
+	if len(conf.allowedLicenses) == 0 && len(conf.deniedLicenses) == 0 {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-licenses",
+		Usage: "Checks licenses of dependencies.",
+		Action: func(a *goyek.A) {
+			lintLicenses(a, conf)
+		},
+	}))
+}
+
+func lintLicenses(a *goyek.A, conf *config) {
+	a.Helper()
+
+	if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+		a.Fatalf("failed to create out directory: %v", err)
+	}
+
+	// go-licenses report writes a CSV of module, license URL, and license name.
+	var report strings.Builder
+	cmdLine := fmt.Sprintf("go run github.com/google/go-licenses@%s report ./...", conf.version("go-licenses", verGoLicenses))
+	if !execCmd(a, cmdLine, cmd.Stdout(&report)) {
+		return
+	}
+	reportPath := filepath.Join(conf.artifactsPath, "licenses.csv")
+	if err := os.WriteFile(reportPath, []byte(report.String()), 0o644); err != nil {
+		a.Errorf("failed to write license report: %v", err)
+	}
+
+	allowed := stringSet(conf.allowedLicenses)
+	denied := stringSet(conf.deniedLicenses)
+	r := csv.NewReader(strings.NewReader(report.String()))
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			a.Fatalf("failed to parse license report: %v", err)
+		}
+		if len(rec) < 3 {
+			continue
+		}
+		mod, license := rec[0], rec[2]
+		switch {
+		case denied[license]:
+			a.Errorf("%s has denied license %s", mod, license)
+		case len(allowed) > 0 && !allowed[license]:
+			a.Errorf("%s has license %s which is not allowed", mod, license)
+		}
+	}
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,250 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// lintIssue is an issue in a golangci-lint JSON report.
+type lintIssue struct {
+	FromLinter string
+	Text       string
+	Pos        struct {
+		Filename string
+		Line     int
+		Column   int
+	}
+}
+
+// golangciLintCommand returns the golangci-lint run command line for the module in dir,
+// without output formats, args configured with GolangCIArgs, or packages. Paths of
+// issues in nested modules are prefixed with the module directory so they are relative
+// to the working directory like other modules.
+func golangciLintCommand(conf *config, dir string) string {
+	cmdLine := fmt.Sprintf("go run github.com/golangci/golangci-lint/cmd/golangci-lint@%s run --timeout=%s", conf.version("golangci-lint", verGolangCILint), conf.lintTimeout())
+	if conf.golangciConfig != "" {
+		cfg, err := filepath.Abs(conf.golangciConfig)
+		if err != nil {
+			cfg = conf.golangciConfig
+		}
+		cmdLine += " --config=" + shellQuote(filepath.ToSlash(cfg))
+	}
+	if dir != "." {
+		cmdLine += " --path-prefix=" + shellQuote(filepath.ToSlash(dir))
+	}
+	if verbose() {
+		cmdLine += " -v"
+	}
+	if n := commands.maxParallel(); n > 0 {
+		cmdLine += fmt.Sprintf(" --concurrency=%d", n)
+	}
+	return cmdLine
+}
+
+// golangciLintTargets returns the end of the golangci-lint command line, the args
+// configured with GolangCIArgs or -golangci-args followed by the packages to lint.
+func golangciLintTargets(conf *config) string {
+	var cmdLine string
+	if len(conf.golangciArgs) > 0 {
+		cmdLine += " " + shellJoin(conf.golangciArgs)
+	}
+	// The flag is already split like a shell command line when the command is run.
+	if *golangciArgsFlag != "" {
+		cmdLine += " " + *golangciArgsFlag
+	}
+	return cmdLine + " " + strings.Join(conf.packages(), " ")
+}
+
+// incrementalFiles returns the files changed since the current branch diverged from
+// the base ref with IncrementalLint, for tools that check the files they are passed
+// rather than supporting it themselves, or else all the files.
+func incrementalFiles(a *goyek.A, conf *config, files []string) []string {
+	a.Helper()
+
+	if !conf.incrementalLint {
+		return files
+	}
+	base, err := gitMergeBase(conf.incrementalLintBase())
+	if err != nil {
+		a.Fatal(err)
+	}
+	changed, err := gitChangedFiles(base)
+	if err != nil {
+		a.Fatal(err)
+	}
+	isChanged := make(map[string]bool, len(changed))
+	for _, f := range changed {
+		isChanged[f] = true
+	}
+	var res []string
+	for _, f := range files {
+		if isChanged[filepath.ToSlash(f)] {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// runGolangCILint runs golangci-lint for lint-go in each module. Under GitHub Actions,
+// findings are also reported as annotations and counted in the step summary. If there
+// is a lint baseline, only issues not in it are reported.
+func runGolangCILint(a *goyek.A, conf *config) {
+	a.Helper()
+
+	var incremental string
+	if conf.incrementalLint {
+		base, err := gitMergeBase(conf.incrementalLintBase())
+		if err != nil {
+			a.Error(err)
+			return
+		}
+		incremental = " --new-from-rev=" + base
+	}
+
+	baseline, err := readLintBaseline(conf.lintBaselinePath())
+	if err != nil {
+		a.Errorf("failed to read lint baseline: %v", err)
+		return
+	}
+	useBaseline := baseline != nil
+
+	dirs, err := conf.goModuleDirs()
+	if err != nil {
+		a.Errorf("failed to find Go modules: %v", err)
+		return
+	}
+	if len(dirs) == 0 {
+		a.Log("no Go modules to lint")
+		return
+	}
+	if conf.lintSARIF && !mkdirSARIF(a, conf) {
+		return
+	}
+	gha := inGitHubActions()
+	// Issues are counted from a JSON report for the step summary and build report.
+	countIssues := gha || conf.buildReport || useBaseline
+
+	var issues []lintIssue
+	ok, counted := true, true
+	for _, dir := range dirs {
+		var formats []string
+		// With a baseline, new issues are printed from the JSON report instead.
+		if !useBaseline {
+			formats = append(formats, "colored-line-number")
+		}
+		if conf.lintSARIF {
+			sarif, err := filepath.Abs(filepath.Join(conf.sarifPath(), "golangci-lint"+moduleSuffix(dir)+".sarif"))
+			if err != nil {
+				a.Errorf("failed to resolve SARIF path: %v", err)
+				return
+			}
+			formats = append(formats, "sarif:"+sarif)
+		}
+		if gha && !useBaseline {
+			formats = append(formats, "github-actions")
+		}
+		issuesPath, err := moduleArtifact(conf, dir, filepath.Join(conf.artifactsPath, "golangci-lint.json"))
+		if err != nil {
+			a.Errorf("failed to resolve golangci-lint report path: %v", err)
+			return
+		}
+		if countIssues {
+			if err := os.MkdirAll(filepath.Dir(issuesPath), 0o755); err != nil {
+				a.Errorf("failed to create out directory: %v", err)
+				return
+			}
+			// Do not count issues from a previous run if golangci-lint fails to start.
+			_ = os.Remove(issuesPath)
+			formats = append(formats, "json:"+issuesPath)
+		}
+
+		cmdLine := golangciLintCommand(conf, dir) + incremental
+		if len(formats) > 1 || useBaseline {
+			cmdLine += " --out-format=" + shellQuote(strings.Join(formats, ","))
+		}
+		if useBaseline {
+			// Issues are only failed on after removing those in the baseline, which must see
+			// every issue to match them.
+			cmdLine += " --issues-exit-code=0 --max-issues-per-linter=0 --max-same-issues=0"
+		}
+		cmdLine += golangciLintTargets(conf)
+
+		modOK := execCmd(a, cmdLine, moduleOpts(dir, nil)...)
+		ok = ok && modOK
+		if !countIssues || dryRun() {
+			continue
+		}
+		modIssues, err := readLintIssues(issuesPath)
+		if err != nil {
+			if modOK {
+				a.Logf("failed to read golangci-lint report: %v", err)
+			}
+			counted = false
+			continue
+		}
+		issues = append(issues, modIssues...)
+	}
+	if !countIssues || dryRun() || !counted {
+		return
+	}
+
+	if useBaseline {
+		issues = newLintIssues(issues, baseline)
+		printLintIssues(a, issues, gha)
+		if len(issues) > 0 {
+			a.Errorf("%d lint issues not in baseline %s", len(issues), conf.lintBaselinePath())
+			ok = false
+		}
+	}
+	recordOutput(a, "issues", len(issues))
+	if !gha {
+		return
+	}
+	summary := "### lint-go\n\n"
+	switch {
+	case len(issues) > 0:
+		summary += fmt.Sprintf(":x: %d issues found\n", len(issues))
+	case !ok:
+		summary += ":x: golangci-lint failed\n"
+	default:
+		summary += ":white_check_mark: No issues found\n"
+	}
+	writeStepSummary(a, summary)
+}
+
+// readLintIssues returns the issues in a golangci-lint JSON report.
+func readLintIssues(path string) ([]lintIssue, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report struct {
+		Issues []lintIssue
+	}
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, err
+	}
+	return report.Issues, nil
+}
+
+// annotationEscaper escapes the message of a GitHub Actions workflow command.
+var annotationEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// printLintIssues prints the issues in the same format as golangci-lint, also as
+// annotations under GitHub Actions.
+func printLintIssues(a *goyek.A, issues []lintIssue, gha bool) {
+	a.Helper()
+
+	out := a.Output()
+	for _, i := range issues {
+		fmt.Fprintf(out, "%s:%d:%d: %s (%s)\n", i.Pos.Filename, i.Pos.Line, i.Pos.Column, i.Text, i.FromLinter)
+		if gha {
+			fmt.Fprintf(out, "::error file=%s,line=%d,col=%d::%s (%s)\n", i.Pos.Filename, i.Pos.Line, i.Pos.Column, annotationEscaper.Replace(i.Text), i.FromLinter)
+		}
+	}
+}
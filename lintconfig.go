@@ -0,0 +1,153 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goyek/goyek/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	lintConfigBegin = "# BEGIN go-build managed section, regenerate with generate-lint-config."
+	lintConfigEnd   = "# END go-build managed section."
+)
+
+// lintConfigFiles are the names of golangci-lint configuration files detected in the
+// working directory, in the order golangci-lint itself searches for them.
+var lintConfigFiles = []string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}
+
+// lintConfigKeys are the top-level keys of the golangci-lint configuration written to
+// the managed section, with the recommended settings, the same as used by go-build
+// itself. Each key is managed as a whole.
+var lintConfigKeys = []struct {
+	name string
+	tmpl *template.Template
+}{
+	{"linters", template.Must(template.New("linters").Parse(`linters:
+  enable:
+    # Style linters are opted into without the preset since many
+    # are too strict (e.g., no init functions allowed).
+    - decorder
+    - dupword
+    - gci
+    - gocritic
+    - gofumpt
+    - goimports
+    - goprintffuncname
+    - inamedparam
+    - mirror
+    - revive
+    - stylecheck
+    - tenv
+    - unconvert
+    - usestdlibvars
+    - wastedassign
+  presets:
+    - bugs
+    - performance
+`))},
+	{"linters-settings", template.Must(template.New("linters-settings").Parse(`linters-settings:
+  gci:
+    sections:
+      - standard
+      - default
+{{- range .Prefixes}}
+      - prefix({{.}})
+{{- end}}
+`))},
+}
+
+// lintConfigPath returns the path of the golangci-lint configuration file, the one set
+// with GolangCIConfig or else the first found in the working directory, defaulting to
+// .golangci.yml for a new file.
+func (c *config) lintConfigPath() string {
+	if c.golangciConfig != "" {
+		return c.golangciConfig
+	}
+	for _, f := range lintConfigFiles {
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return lintConfigFiles[0]
+}
+
+// defineLintConfigTask defines the generate-lint-config task. It is not run by generate
+// since repositories may manage their golangci-lint configuration themselves.
+func defineLintConfigTask(conf *config) {
+	goyek.Define(goyek.Task{
+		Name:  "generate-lint-config",
+		Usage: "Writes the recommended linters to the managed section of the golangci-lint configuration.",
+		Action: func(a *goyek.A) {
+			generateLintConfig(a, conf)
+		},
+	})
+}
+
+// generateLintConfig creates the golangci-lint configuration, or replaces the managed
+// section of an existing one, leaving the rest of the file, e.g. issues or run settings,
+// as is. Existing files without the section are not modified since the recommended
+// settings may conflict with their own. Keys set outside the section are left to the
+// file, so are not written to it.
+func generateLintConfig(a *goyek.A, conf *config) {
+	a.Helper()
+
+	path := conf.lintConfigPath()
+	if ext := filepath.Ext(path); ext != ".yml" && ext != ".yaml" {
+		a.Fatalf("%s is not YAML, generate-lint-config only supports YAML configuration", path)
+	}
+
+	var existing, before, after string
+	b, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		a.Fatalf("failed to read %s: %v", path, err)
+	default:
+		existing = string(b)
+		begin := strings.Index(existing, lintConfigBegin)
+		end := strings.Index(existing, lintConfigEnd)
+		if begin < 0 || end < begin {
+			a.Fatalf(`%s has no managed section, add the lines %q and %q where the recommended linters and linters-settings should be written`, path, lintConfigBegin, lintConfigEnd)
+		}
+		end += len(lintConfigEnd)
+		if end < len(existing) && existing[end] == '\n' {
+			end++
+		}
+		before, after = existing[:begin], existing[end:]
+	}
+
+	var userKeys map[string]any
+	if err := yaml.Unmarshal([]byte(before+after), &userKeys); err != nil {
+		a.Fatalf("failed to parse %s: %v", path, err)
+	}
+	var keys []string
+	for _, k := range lintConfigKeys {
+		if _, ok := userKeys[k.name]; ok {
+			a.Logf("%s is set outside the managed section, not writing it", k.name)
+			continue
+		}
+		var sb strings.Builder
+		if err := k.tmpl.Execute(&sb, struct{ Prefixes []string }{conf.localPackagePrefixes}); err != nil {
+			a.Fatalf("failed to render lint config: %v", err)
+		}
+		keys = append(keys, sb.String())
+	}
+	content := before + lintConfigBegin + "\n" + strings.Join(keys, "\n") + lintConfigEnd + "\n" + after
+	if content == existing {
+		a.Logf("%s is up to date", path)
+		return
+	}
+
+	if dryRun() {
+		a.Log("Write (dry run): ", path)
+		return
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		a.Fatalf("failed to write %s: %v", path, err)
+	}
+	a.Logf("wrote %s", path)
+}
@@ -0,0 +1,125 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/goyek/v2/middleware"
+	// Defines the standard goyek flags read by Main. Using boot's flags rather than
+	// defining them here keeps builds that import boot directly working.
+	_ "github.com/goyek/x/boot"
+	"github.com/goyek/x/color"
+)
+
+// Main parses flags and runs the requested tasks, exiting when they finish. It is
+// equivalent to boot.Main, reading the same flags, with any middlewares configured
+// by DefineTasks, so that a build's main function only needs to call DefineTasks
+// followed by Main. With -dry-run, tasks log the commands they would run, including
+// their environment and working directory, without running them. With -keep-going,
+// tasks keep running after a failure, except those depending on the failed task, and
+// the failures are listed at the end. With -q, only failures are printed.
+func Main() {
+	flag.CommandLine.SetOutput(goyek.Output())
+	flag.Usage = usage
+	flag.Parse()
+
+	v := verbose()
+	// Unlike boot.Main, task actions are still run with -dry-run so that they log the
+	// commands they would run, while the commands themselves are skipped.
+	if dryRun() {
+		v = true // needed to print the commands
+	}
+
+	goyek.Use(color.ReportStatus)
+	if v {
+		goyek.Use(middleware.BufferParallel)
+	} else {
+		goyek.Use(middleware.SilentNonFailed)
+	}
+	if longRun := durationFlag("long-run"); longRun > 0 {
+		goyek.Use(middleware.ReportLongRun(longRun))
+	}
+	if boolFlag("no-color") {
+		color.NoColor()
+	}
+	// Used last so failures are reported before being hidden from the flow.
+	keepGoing = *keepGoingFlag || definedConfig != nil && definedConfig.keepGoing
+	if keepGoing {
+		goyek.Use(continueOnFailure)
+	}
+
+	var opts []goyek.Option
+	if boolFlag("no-deps") {
+		opts = append(opts, goyek.NoDeps())
+	}
+	if skip := flag.Lookup("skip").Value.String(); skip != "" {
+		opts = append(opts, goyek.Skip(strings.Split(skip, ",")...))
+	}
+
+	goyek.SetUsage(usage)
+	goyek.SetLogger(&color.CodeLineLogger{})
+	os.Exit(run(flag.Args(), opts))
+}
+
+// run executes the tasks, returning the exit code for the result. The first interrupt
+// cancels the run and the second exits immediately.
+func run(tasks []string, opts []goyek.Option) int {
+	out := goyek.Output()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		<-c
+		fmt.Fprintln(out, "first interrupt, graceful stop")
+		cancel()
+
+		<-c
+		fmt.Fprintln(out, "second interrupt, exit")
+		os.Exit(1)
+	}()
+
+	start := time.Now()
+	err := goyek.Execute(ctx, tasks, opts...)
+	var failErr *goyek.FailError
+	switch {
+	case errors.As(err, &failErr), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintf(out, "%v\t%.3fs\n", err, time.Since(start).Seconds())
+		return 1
+	case err != nil:
+		fmt.Fprintln(out, err.Error())
+		usage()
+		return 2
+	}
+	if keepGoing && printFailures(out) {
+		fmt.Fprintf(out, "tasks failed\t%.3fs\n", time.Since(start).Seconds())
+		return 1
+	}
+	if !quiet() {
+		fmt.Fprintf(out, "ok\t%.3fs\n", time.Since(start).Seconds())
+	}
+	return 0
+}
+
+func usage() {
+	fmt.Println("Usage of build: [flags] [--] [tasks]")
+	goyek.Print()
+	fmt.Println("Flags:")
+	flag.PrintDefaults()
+}
+
+func boolFlag(name string) bool {
+	return flag.Lookup(name).Value.(flag.Getter).Get().(bool)
+}
+
+func durationFlag(name string) time.Duration {
+	return flag.Lookup(name).Value.(flag.Getter).Get().(time.Duration)
+}
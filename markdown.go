@@ -0,0 +1,93 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goyek/goyek/v2"
+)
+
+var defaultMarkdownPatterns = []string{"**/*.md"}
+
+func (c *config) markdownPatterns() []string {
+	if len(c.markdownFiles) > 0 {
+		return c.markdownFiles
+	}
+	return defaultMarkdownPatterns
+}
+
+// prettier returns the command line to run prettier.
+func (c *config) prettier() string {
+	return fmt.Sprintf("go run github.com/wasilibs/go-prettier/v3/cmd/prettier@%s", c.version("prettier", verPrettier))
+}
+
+// defineMarkdownTasks defines tasks for Markdown files if enabled with MarkdownTasks and
+// the repository contains any.
+func defineMarkdownTasks(conf *config) {
+	if !conf.markdownTasks || len(globFiles(conf, conf.markdownPatterns()...)) == 0 {
+		return
+	}
+
+	RegisterFormatTask(goyek.Define(goyek.Task{
+		Name:  "format-markdown",
+		Usage: "Formats Markdown files with prettier.",
+		Action: func(a *goyek.A) {
+			runPrettier(a, conf, globFiles(conf, conf.markdownPatterns()...))
+		},
+	}))
+}
+
+// prettierConfigFiles are the names of prettier configuration files detected in the
+// working directory, in the order prettier itself searches for them.
+var prettierConfigFiles = []string{
+	".prettierrc",
+	".prettierrc.json",
+	".prettierrc.yaml",
+	".prettierrc.yml",
+	".prettierrc.json5",
+	".prettierrc.js",
+	".prettierrc.mjs",
+	".prettierrc.cjs",
+	"prettier.config.js",
+	"prettier.config.mjs",
+	"prettier.config.cjs",
+	".prettierrc.toml",
+}
+
+// prettierConfig returns the prettier configuration file to use, the one set with
+// PrettierConfig or else the first found in the working directory, or empty to use
+// prettier's defaults.
+func (c *config) prettierConfig() string {
+	if c.prettierConfigPath != "" {
+		return c.prettierConfigPath
+	}
+	for _, f := range prettierConfigFiles {
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return ""
+}
+
+// runPrettier formats the files with prettier, or only checks them with -check. With
+// IncrementalLint, only changed files are formatted.
+func runPrettier(a *goyek.A, conf *config, files []string) {
+	a.Helper()
+
+	files = incrementalFiles(a, conf, files)
+	cmdLine := conf.prettier()
+	if cfg := conf.prettierConfig(); cfg != "" {
+		cmdLine += " --config " + shellQuote(filepath.ToSlash(cfg))
+	}
+	// Files are passed explicitly so ignored files must be skipped with
+	// --ignore-path rather than relying on prettier's own directory expansion.
+	if _, err := os.Stat(".prettierignore"); err == nil {
+		cmdLine += " --ignore-path .prettierignore"
+	}
+	if conf.formatCheckOnly() {
+		execFiles(a, cmdLine+" --check", files)
+		return
+	}
+	execFiles(a, cmdLine+" --write", files)
+}
@@ -0,0 +1,156 @@
+package build
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// goModules returns the directories of all Go modules in the working directory,
+// including nested ones such as the build module itself. Modules in testdata and
+// those excluded with ExcludeModules are skipped.
+func goModules(conf *config) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != "." && (skipDir(conf, path) || d.Name() == "testdata" || excludedModule(conf, path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "go.mod" {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// excludedModule returns whether the directory matches any of the doublestar patterns
+// configured with ExcludeModules.
+func excludedModule(conf *config, dir string) bool {
+	slashDir := filepath.ToSlash(dir)
+	for _, p := range conf.excludeModules {
+		if ok, _ := doublestar.Match(p, slashDir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// goModuleDirs returns the directories of the modules to run Go tasks like lint-go and
+// test-go in, the modules of allGoModuleDirs, filtered to those affected by changes with
+// -affected or AffectedModules.
+func (c *config) goModuleDirs() ([]string, error) {
+	dirs, err := c.allGoModuleDirs()
+	if err != nil || !c.affectedOnly() || c.moduleDir != "" {
+		return dirs, err
+	}
+	return affectedModules(c, dirs)
+}
+
+// allGoModuleDirs returns the directories of the modules Go tasks apply to, only the
+// module of a namespaced task such as test:services/api, or the modules of the go.work
+// workspace if there is one. If packages are set with Packages, only the module in the
+// working directory is used since the packages are relative to it.
+func (c *config) allGoModuleDirs() ([]string, error) {
+	if c.moduleDir != "" {
+		return []string{c.moduleDir}, nil
+	}
+	if len(c.pkgs) > 0 {
+		return []string{"."}, nil
+	}
+	dirs, ok, err := workspaceModules(c)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return dirs, nil
+	}
+	return goModules(c)
+}
+
+// moduleOpts returns the options to run a command in the module directory, the working
+// directory if empty.
+func moduleOpts(dir string, opts []cmd.Option) []cmd.Option {
+	if dir == "." || dir == "" {
+		return opts
+	}
+	return append(append([]cmd.Option{}, opts...), cmd.Dir(dir))
+}
+
+// moduleArtifact returns the path of an artifact of the module, base itself for the
+// module in the working directory, or within modules in the artifacts directory for
+// nested modules. The path is absolute since commands run in the module directory.
+func moduleArtifact(conf *config, dir string, base string) (string, error) {
+	if dir == "." {
+		return base, nil
+	}
+	return filepath.Abs(filepath.Join(conf.artifactsPath, "modules", dir, filepath.Base(base)))
+}
+
+// moduleSuffix returns a suffix for artifact names of the module in dir, empty for the
+// module in the working directory, e.g. "-services-api" for services/api.
+func moduleSuffix(dir string) string {
+	if dir == "." {
+		return ""
+	}
+	return "-" + strings.ReplaceAll(filepath.ToSlash(dir), "/", "-")
+}
+
+// defineModuleTasks defines lint-go and test tasks namespaced by module directory, e.g.
+// lint-go:libs/core and test:services/api, for each nested module, to run checks of a
+// single module from the repository root. The aggregate tasks still run all modules.
+// If the modules cannot be found, the tasks are not defined and lint-go and test report
+// the error when run.
+func defineModuleTasks(conf *config) {
+	dirs, err := conf.allGoModuleDirs()
+	if err != nil {
+		fmt.Fprintf(goyek.Output(), "WARNING: not defining tasks for nested modules: failed to find Go modules: %v\n", err)
+		return
+	}
+	excluded := map[string]bool{}
+	for _, name := range conf.excludeTasks {
+		excluded[name] = true
+	}
+	for _, dir := range dirs {
+		if dir == "." {
+			continue
+		}
+		name := filepath.ToSlash(dir)
+		modConf := *conf
+		modConf.moduleDir = dir
+
+		if !excluded["lint-go"] {
+			goyek.Define(goyek.Task{
+				Name:  "lint-go:" + name,
+				Usage: "Lints Go code of the module in " + name + ".",
+				Action: func(a *goyek.A) {
+					runGolangCILint(a, &modConf)
+				},
+			})
+		}
+		if !excluded["test-go"] {
+			goyek.Define(goyek.Task{
+				Name:  "test:" + name,
+				Usage: "Runs Go unit tests of the module in " + name + ".",
+				Action: func(a *goyek.A) {
+					runTests(a, &modConf)
+				},
+			})
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// nfpmConfig is the subset of the nfpm configuration file used for packaging.
+type nfpmConfig struct {
+	Name          string        `yaml:"name"`
+	Arch          string        `yaml:"arch"`
+	Platform      string        `yaml:"platform"`
+	Version       string        `yaml:"version"`
+	VersionSchema string        `yaml:"version_schema"`
+	Maintainer    string        `yaml:"maintainer,omitempty"`
+	Description   string        `yaml:"description,omitempty"`
+	Homepage      string        `yaml:"homepage,omitempty"`
+	License       string        `yaml:"license,omitempty"`
+	Contents      []nfpmContent `yaml:"contents"`
+}
+
+type nfpmContent struct {
+	Src string `yaml:"src"`
+	Dst string `yaml:"dst"`
+}
+
+// packageLinux builds packages in each configured format for each linux target from the
+// binaries built by build-go, writing them to the packages directory in the artifacts
+// directory.
+func packageLinux(a *goyek.A, conf *config) {
+	a.Helper()
+
+	pkgDir := filepath.Join(conf.artifactsPath, "packages")
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		a.Fatalf("failed to create packages directory: %v", err)
+	}
+
+	project := projectName()
+	nfpm := fmt.Sprintf("go run github.com/goreleaser/nfpm/v2/cmd/nfpm@%s", conf.version("nfpm", verNFPM))
+	for _, target := range conf.targets() {
+		goos, goarch, _ := strings.Cut(target, "/")
+		if goos != "linux" {
+			continue
+		}
+		binDir := filepath.Join(conf.artifactsPath, "bin", goos+"_"+goarch)
+		files, err := os.ReadDir(binDir)
+		if err != nil {
+			a.Errorf("failed to read binaries for %s: %v", target, err)
+			continue
+		}
+
+		cfg := nfpmConfig{
+			Name:     project,
+			Arch:     goarch,
+			Platform: goos,
+			// Versions from git describe are not always semantic versions, so they are
+			// used as is.
+			Version:       strings.TrimPrefix(gitVersion(), "v"),
+			VersionSchema: "none",
+			Maintainer:    conf.packageInfo.maintainer,
+			Description:   conf.packageInfo.description,
+			Homepage:      conf.packageInfo.homepage,
+			License:       conf.packageInfo.license,
+		}
+		for _, f := range files {
+			if !f.IsDir() {
+				cfg.Contents = append(cfg.Contents, nfpmContent{
+					Src: filepath.ToSlash(filepath.Join(binDir, f.Name())),
+					Dst: "/usr/bin/" + f.Name(),
+				})
+			}
+		}
+
+		content, err := yaml.Marshal(cfg)
+		if err != nil {
+			a.Fatalf("failed to encode nfpm config: %v", err)
+		}
+		cfgFile := filepath.Join(pkgDir, "nfpm_"+goarch+".yaml")
+		if err := os.WriteFile(cfgFile, content, 0o644); err != nil {
+			a.Errorf("failed to write nfpm config: %v", err)
+			continue
+		}
+		for _, format := range conf.linuxPackageFormats {
+			execCmd(a, fmt.Sprintf("%s package %s", nfpm, shellJoin([]string{"-f", cfgFile, "-p", format, "-t", pkgDir + string(filepath.Separator)})))
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package build
+
+// quiet returns whether only failures should be printed, set with -q or Quiet.
+func quiet() bool {
+	return *quietFlag || definedConfig != nil && definedConfig.quiet
+}
+
+// verbose returns whether all tasks and the full output of commands should be printed,
+// set with -v or Verbose.
+func verbose() bool {
+	return !quiet() && (boolFlag("v") || definedConfig != nil && definedConfig.verbose)
+}
@@ -0,0 +1,43 @@
+package build
+
+import (
+	"context"
+	"sync"
+)
+
+// cmdLimiter limits the number of commands run at once across tasks.
+type cmdLimiter struct {
+	// limit is set by the MaxParallel option and overridden by -max-parallel.
+	limit int
+
+	once sync.Once
+	sem  chan struct{}
+}
+
+var commands = &cmdLimiter{}
+
+// maxParallel returns the configured limit, or 0 if there is none.
+func (l *cmdLimiter) maxParallel() int {
+	if *maxParallelFlag > 0 {
+		return *maxParallelFlag
+	}
+	return l.limit
+}
+
+// acquire waits until a command can be run, returning a function to release it.
+func (l *cmdLimiter) acquire(ctx context.Context) (func(), error) {
+	l.once.Do(func() {
+		if n := l.maxParallel(); n > 0 {
+			l.sem = make(chan struct{}, n)
+		}
+	})
+	if l.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
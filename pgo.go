@@ -0,0 +1,129 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// defaultPGOMaxAge is the default age after which checked-in profiles are considered
+// stale.
+const defaultPGOMaxAge = 90 * 24 * time.Hour
+
+// definePGOTasks defines the tasks for managing profiles used for profile-guided
+// optimization if any main packages are configured.
+func definePGOTasks(conf *config) {
+	if len(conf.pgoPackages) == 0 {
+		return
+	}
+
+	goyek.Define(goyek.Task{
+		Name:  "generate-pgo",
+		Usage: "Collects CPU profiles from benchmarks and merges them into default.pgo for configured main packages.",
+		Action: func(a *goyek.A) {
+			generatePGO(a, conf)
+		},
+	})
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-pgo",
+		Usage: "Checks that PGO profiles exist and are not stale.",
+		Action: func(a *goyek.A) {
+			lintPGO(a, conf)
+		},
+	}))
+}
+
+// generatePGO runs benchmarks for each package with a CPU profile, since go test only
+// supports profiling a single package at a time, and merges the profiles.
+func generatePGO(a *goyek.A, conf *config) {
+	a.Helper()
+
+	profileDir := filepath.Join(conf.artifactsPath, "pgo")
+	if err := os.RemoveAll(profileDir); err != nil {
+		a.Fatalf("failed to clear profile directory: %v", err)
+	}
+	if err := os.MkdirAll(profileDir, 0o755); err != nil {
+		a.Fatalf("failed to create profile directory: %v", err)
+	}
+
+	var list strings.Builder
+	if !execCmd(a, `go list -f "{{if .TestGoFiles}}{{.ImportPath}}{{end}}" `+strings.Join(conf.packages(), " "), cmd.Stdout(&list)) {
+		return
+	}
+	filter := conf.benchFilter
+	if filter == "" {
+		filter = "."
+	}
+	var profiles []string
+	for i, pkg := range strings.Fields(list.String()) {
+		profile := filepath.Join(profileDir, strconv.Itoa(i)+".pprof")
+		// Profiling keeps the test binary, which is written next to the profile instead
+		// of the working directory.
+		bin := filepath.Join(profileDir, strconv.Itoa(i)+".test")
+		if !execCmd(a, fmt.Sprintf("go test -run=^$ %s", shellJoin([]string{"-bench=" + filter, "-cpuprofile=" + profile, "-o", bin, pkg}))) {
+			return
+		}
+		profiles = append(profiles, profile)
+	}
+	if len(profiles) == 0 {
+		a.Skip("no packages with tests to profile")
+	}
+
+	var out bytes.Buffer
+	if !execCmd(a, "go tool pprof -proto "+shellJoin(profiles), cmd.Stdout(&out)) {
+		return
+	}
+	merged := filepath.Join(profileDir, "merged.pgo")
+	if dryRun() {
+		for _, dir := range conf.pgoPackages {
+			a.Log("Write (dry run): ", filepath.Join(dir, "default.pgo"))
+		}
+		return
+	}
+	if err := os.WriteFile(merged, out.Bytes(), 0o644); err != nil {
+		a.Fatalf("failed to write merged profile: %v", err)
+	}
+	for _, dir := range conf.pgoPackages {
+		dst := filepath.Join(dir, "default.pgo")
+		if err := copyToFile(dst, merged); err != nil {
+			a.Errorf("failed to write %s: %v", dst, err)
+			continue
+		}
+		a.Logf("wrote %s", dst)
+	}
+}
+
+// lintPGO fails the task if the profile of any configured main package is missing or
+// was last committed longer ago than the maximum age.
+func lintPGO(a *goyek.A, conf *config) {
+	a.Helper()
+
+	maxAge := conf.pgoMaxAge
+	if maxAge == 0 {
+		maxAge = defaultPGOMaxAge
+	}
+	for _, dir := range conf.pgoPackages {
+		profile := filepath.Join(dir, "default.pgo")
+		if !fileExists(profile) {
+			a.Errorf("missing PGO profile %s, run generate-pgo", profile)
+			continue
+		}
+		// Modification times are not preserved by checkouts, so the commit time is used.
+		// Profiles that are not committed yet are new.
+		committed, err := gitCommitTime(profile)
+		if err != nil {
+			continue
+		}
+		if age := time.Since(committed); age > maxAge {
+			a.Errorf("PGO profile %s is %d days old, more than %d days, run generate-pgo", profile, int(age.Hours()/24), int(maxAge.Hours()/24))
+		}
+	}
+}
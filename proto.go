@@ -0,0 +1,63 @@
+package build
+
+import (
+	"fmt"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineProtoTasks defines tasks for protobuf files using buf if the repository
+// contains any.
+func defineProtoTasks(conf *config) {
+	if !fileExists("buf.yaml") && len(findFiles(conf, "*.proto")) == 0 {
+		return
+	}
+
+	RegisterFormatTask(goyek.Define(goyek.Task{
+		Name:  "format-proto",
+		Usage: "Formats protobuf files.",
+		Action: func(a *goyek.A) {
+			if conf.formatCheckOnly() {
+				execCmd(a, fmt.Sprintf("%s format --diff --exit-code", conf.buf()))
+				return
+			}
+			execCmd(a, fmt.Sprintf("%s format -w", conf.buf()))
+		},
+	}))
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-proto",
+		Usage: "Lints protobuf files.",
+		Action: func(a *goyek.A) {
+			execCmd(a, fmt.Sprintf("%s lint", conf.buf()))
+		},
+	}))
+
+	if conf.protoBreakingAgainst != "" || inGitRepo() {
+		RegisterLintTask(goyek.Define(goyek.Task{
+			Name:  "lint-proto-breaking",
+			Usage: "Checks protobuf files for breaking changes.",
+			Action: func(a *goyek.A) {
+				against := conf.protoBreakingAgainst
+				if against == "" {
+					against = ".git#branch=main"
+				}
+				execCmd(a, fmt.Sprintf("%s breaking --against %s", conf.buf(), against))
+			},
+		}))
+	}
+
+	if fileExists("buf.gen.yaml") {
+		RegisterGenerateTask(goyek.Define(goyek.Task{
+			Name:  "generate-proto",
+			Usage: "Generates code from protobuf files.",
+			Action: func(a *goyek.A) {
+				execCmd(a, fmt.Sprintf("%s generate", conf.buf()))
+			},
+		}))
+	}
+}
+
+func (c *config) buf() string {
+	return fmt.Sprintf("go run github.com/bufbuild/buf/cmd/buf@%s", c.version("buf", verBuf))
+}
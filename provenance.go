@@ -0,0 +1,159 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// provenanceBuildType identifies builds by go-build in provenance statements.
+const provenanceBuildType = "https://github.com/curioswitch/go-build/release@v1"
+
+type inTotoStatement struct {
+	Type          string              `json:"_type"`
+	Subject       []inTotoSubject     `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     provenancePredicate `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenancePredicate struct {
+	BuildDefinition provenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      provenanceRunDetails      `json:"runDetails"`
+}
+
+type provenanceBuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]any         `json:"externalParameters"`
+	InternalParameters   map[string]any         `json:"internalParameters,omitempty"`
+	ResolvedDependencies []provenanceDependency `json:"resolvedDependencies"`
+}
+
+type provenanceDependency struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type provenanceRunDetails struct {
+	Builder  provenanceBuilder  `json:"builder"`
+	Metadata provenanceMetadata `json:"metadata"`
+}
+
+type provenanceBuilder struct {
+	ID string `json:"id"`
+}
+
+type provenanceMetadata struct {
+	InvocationID string `json:"invocationId,omitempty"`
+	FinishedOn   string `json:"finishedOn"`
+}
+
+// writeProvenance writes a SLSA v1 provenance statement covering the release files
+// into the release directory, describing the builder, source commit, and Go module
+// dependencies.
+func writeProvenance(a *goyek.A, conf *config) {
+	a.Helper()
+
+	releaseDir := filepath.Join(conf.artifactsPath, "release")
+	name := fmt.Sprintf("%s_%s.intoto.jsonl", projectName(), strings.TrimPrefix(gitVersion(), "v"))
+	if dryRun() {
+		// The release files covered are not packaged with -dry-run.
+		a.Log("Write (dry run): ", filepath.Join(releaseDir, name))
+		return
+	}
+	entries, err := os.ReadDir(releaseDir)
+	if err != nil {
+		a.Fatalf("failed to read release directory, run release first: %v", err)
+	}
+
+	stmt := inTotoStatement{
+		Type:          "https://in-toto.io/Statement/v1",
+		PredicateType: "https://slsa.dev/provenance/v1",
+	}
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == name || strings.HasSuffix(e.Name(), ".bundle") {
+			continue
+		}
+		h := sha256.New()
+		if err := copyFile(h, filepath.Join(releaseDir, e.Name())); err != nil {
+			a.Fatalf("failed to hash %s: %v", e.Name(), err)
+		}
+		stmt.Subject = append(stmt.Subject, inTotoSubject{
+			Name:   e.Name(),
+			Digest: map[string]string{"sha256": hex.EncodeToString(h.Sum(nil))},
+		})
+	}
+
+	var goVersion strings.Builder
+	if !execCmd(a, "go env GOVERSION", cmd.Stdout(&goVersion)) {
+		return
+	}
+	var mods strings.Builder
+	if !execCmd(a, "go list -m -f {{if.Version}}{{.Path}}@{{.Version}}{{end}} all", cmd.Stdout(&mods)) {
+		return
+	}
+
+	source := gitRepoURL()
+	commit, _ := gitCommit()
+	deps := []provenanceDependency{{
+		URI:    "git+" + source,
+		Digest: map[string]string{"gitCommit": commit},
+	}}
+	for _, mod := range strings.Fields(mods.String()) {
+		deps = append(deps, provenanceDependency{URI: "pkg:golang/" + mod})
+	}
+
+	stmt.Predicate = provenancePredicate{
+		BuildDefinition: provenanceBuildDefinition{
+			BuildType: provenanceBuildType,
+			ExternalParameters: map[string]any{
+				"source":  source,
+				"targets": conf.targets(),
+			},
+			InternalParameters: map[string]any{
+				"goVersion": strings.TrimSpace(goVersion.String()),
+			},
+			ResolvedDependencies: deps,
+		},
+		RunDetails: provenanceRunDetails{
+			Builder: provenanceBuilder{ID: provenanceBuilderID()},
+			Metadata: provenanceMetadata{
+				InvocationID: os.Getenv("GITHUB_RUN_ID"),
+				FinishedOn:   time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+	}
+
+	content, err := json.Marshal(stmt)
+	if err != nil {
+		a.Fatalf("failed to encode provenance: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(releaseDir, name), append(content, '\n'), 0o644); err != nil {
+		a.Fatalf("failed to write provenance: %v", err)
+	}
+	a.Logf("wrote %s", name)
+}
+
+// provenanceBuilderID returns the ID of the builder running the build, the workflow
+// when running in GitHub Actions.
+func provenanceBuilderID() string {
+	if ref := os.Getenv("GITHUB_WORKFLOW_REF"); ref != "" {
+		return os.Getenv("GITHUB_SERVER_URL") + "/" + ref
+	}
+	if host, err := os.Hostname(); err == nil {
+		return "local:" + host
+	}
+	return "local"
+}
@@ -0,0 +1,254 @@
+package build
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// release packages the binaries built by build-go into one archive per target and
+// writes a SHA256SUMS file covering all of them and any other release files.
+func release(a *goyek.A, conf *config) {
+	a.Helper()
+
+	releaseDir := filepath.Join(conf.artifactsPath, "release")
+	if dryRun() {
+		// The binaries to archive are not built with -dry-run.
+		a.Log("Write (dry run): ", releaseDir)
+		return
+	}
+	if err := os.RemoveAll(releaseDir); err != nil {
+		a.Fatalf("failed to clear release directory: %v", err)
+	}
+	if err := os.MkdirAll(releaseDir, 0o755); err != nil {
+		a.Fatalf("failed to create release directory: %v", err)
+	}
+
+	project := projectName()
+	version := strings.TrimPrefix(gitVersion(), "v")
+
+	// For reproducible builds, archive metadata is normalized so archives of the same
+	// binaries are identical.
+	var modTime time.Time
+	if conf.reproducibleBuilds {
+		modTime = buildTime(conf)
+	}
+
+	var archives []string
+	for _, target := range conf.targets() {
+		goos, goarch, _ := strings.Cut(target, "/")
+		binDir := filepath.Join(conf.artifactsPath, "bin", goos+"_"+goarch)
+		files, err := os.ReadDir(binDir)
+		if err != nil {
+			a.Errorf("failed to read binaries for %s: %v", target, err)
+			continue
+		}
+		var binaries []string
+		for _, f := range files {
+			if !f.IsDir() {
+				binaries = append(binaries, filepath.Join(binDir, f.Name()))
+			}
+		}
+
+		name := fmt.Sprintf("%s_%s_%s_%s", project, version, goos, goarch)
+		if goos == "windows" {
+			name += ".zip"
+			err = writeZip(filepath.Join(releaseDir, name), binaries, modTime)
+		} else {
+			name += ".tar.gz"
+			err = writeTarGz(filepath.Join(releaseDir, name), binaries, modTime)
+		}
+		if err != nil {
+			a.Errorf("failed to create archive %s: %v", name, err)
+			continue
+		}
+		a.Logf("created %s", name)
+		archives = append(archives, name)
+	}
+
+	if conf.releaseSBOM {
+		for _, format := range conf.formatsSBOM() {
+			name := fmt.Sprintf("%s_%s_%s", project, version, sbomFileName(format))
+			if err := copyToFile(filepath.Join(releaseDir, name), filepath.Join(conf.sbomPath(), sbomFileName(format))); err != nil {
+				a.Errorf("failed to copy SBOM: %v", err)
+				continue
+			}
+			archives = append(archives, name)
+		}
+	}
+
+	if err := writeChecksums(releaseDir, archives); err != nil {
+		a.Errorf("failed to write checksums: %v", err)
+	}
+}
+
+// writeTarGz writes files into a gzipped tar. If modTime is not zero, it is used as the
+// modification time of all files and ownership is cleared.
+func writeTarGz(archivePath string, files []string, modTime time.Time) error {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		if !modTime.IsZero() {
+			hdr.ModTime = modTime
+			hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+			hdr.Mode = 0o755
+			hdr.Uid, hdr.Gid = 0, 0
+			hdr.Uname, hdr.Gname = "", ""
+			hdr.Format = tar.FormatUSTAR
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if err := copyFile(tw, file); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeZip writes files into a zip. If modTime is not zero, it is used as the
+// modification time of all files.
+func writeZip(archivePath string, files []string, modTime time.Time) error {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		hdr.Method = zip.Deflate
+		if !modTime.IsZero() {
+			hdr.Modified = modTime.UTC()
+			hdr.SetMode(0o755)
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(w, file); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func copyFile(w io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func copyToFile(dst string, src string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := copyFile(f, src); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeChecksums(dir string, files []string) error {
+	sort.Strings(files)
+	var sb strings.Builder
+	for _, name := range files {
+		h := sha256.New()
+		if err := copyFile(h, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s  %s\n", hex.EncodeToString(h.Sum(nil)), name)
+	}
+	return os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(sb.String()), 0o644)
+}
+
+// projectName returns the name of the project as the last element of the module path,
+// ignoring any major version suffix, falling back to the name of the working directory.
+func projectName() string {
+	mod := modulePath()
+	if mod == "" {
+		wd, _ := os.Getwd()
+		return filepath.Base(wd)
+	}
+	return importPathName(mod)
+}
+
+// importPathName returns the last element of the import path, ignoring any major
+// version suffix, which is also the name go install uses for binaries.
+func importPathName(p string) string {
+	name := path.Base(p)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(p))
+	}
+	return name
+}
+
+// modulePath returns the module path declared in go.mod in the working directory.
+func modulePath() string {
+	f, err := os.Open("go.mod")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if mod, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(mod), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineReleaseNotesTask defines the release-notes task when in a git repository.
+func defineReleaseNotesTask(conf *config) {
+	if !inGitRepo() {
+		return
+	}
+
+	goyek.Define(goyek.Task{
+		Name:  "release-notes",
+		Usage: "Renders release notes for the current version to release-notes.md in the artifacts directory.",
+		Action: func(a *goyek.A) {
+			writeReleaseNotes(a, conf)
+		},
+	})
+}
+
+// writeReleaseNotes writes Markdown release notes with any configured highlights, the
+// changes since the last tag, contributors, and checksums of release artifacts if the
+// release task has been run.
+func writeReleaseNotes(a *goyek.A, conf *config) {
+	a.Helper()
+
+	version, changes, err := gitChanges()
+	if err != nil {
+		a.Fatal(err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", version)
+
+	if conf.releaseNotesHighlights != "" {
+		highlights, err := os.ReadFile(conf.releaseNotesHighlights)
+		if err != nil {
+			a.Fatalf("failed to read release highlights: %v", err)
+		}
+		fmt.Fprintf(&sb, "\n## Highlights\n\n%s\n", strings.TrimSpace(string(highlights)))
+	}
+
+	if len(changes) > 0 {
+		sb.WriteString("\n## Changes\n")
+		writeChanges(&sb, changes, conf.changelogSections(), gitRepoURL())
+	}
+
+	authors := map[string]bool{}
+	for _, c := range changes {
+		authors[c.author] = true
+	}
+	if len(authors) > 0 {
+		names := make([]string, 0, len(authors))
+		for name := range authors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString("\n## Contributors\n\n")
+		for _, name := range names {
+			fmt.Fprintf(&sb, "- %s\n", name)
+		}
+	}
+
+	sums, err := os.ReadFile(filepath.Join(conf.artifactsPath, "release", "SHA256SUMS"))
+	if err == nil {
+		fmt.Fprintf(&sb, "\n## Checksums\n\n```\n%s```\n", sums)
+	}
+
+	file := filepath.Join(conf.artifactsPath, "release-notes.md")
+	if dryRun() {
+		a.Log("Write (dry run): ", file)
+		return
+	}
+	if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+		a.Fatalf("failed to create out directory: %v", err)
+	}
+	if err := os.WriteFile(file, []byte(sb.String()), 0o644); err != nil {
+		a.Fatalf("failed to write release notes: %v", err)
+	}
+	a.Logf("wrote %s", file)
+}
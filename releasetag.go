@@ -0,0 +1,117 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineReleaseTagTask defines the release-tag task when in a git repository.
+func defineReleaseTagTask(conf *config) {
+	if !inGitRepo() {
+		return
+	}
+
+	goyek.Define(goyek.Task{
+		Name:  "release-tag",
+		Usage: "Tags the current commit with the next semantic version, or the one set with -version.",
+		Action: func(a *goyek.A) {
+			releaseTag(a, conf)
+		},
+	})
+}
+
+func releaseTag(a *goyek.A, conf *config) {
+	a.Helper()
+
+	if out, err := exec.Command("git", "describe", "--tags", "--exact-match", "HEAD").Output(); err == nil {
+		a.Fatalf("current commit is already tagged %s", strings.TrimSpace(string(out)))
+	}
+
+	version := *releaseVersionFlag
+	if version == "" {
+		_, changes, err := gitChanges()
+		if err != nil {
+			a.Fatal(err)
+		}
+		prev := "v0.0.0"
+		if out, err := exec.Command("git", "describe", "--tags", "--abbrev=0").Output(); err == nil {
+			prev = strings.TrimSpace(string(out))
+		}
+		version, err = nextVersion(prev, changes)
+		if err != nil {
+			a.Fatal(err)
+		}
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	if !execCmd(a, fmt.Sprintf("git tag -a %s -m %s", version, shellJoin([]string{"Release " + version}))) {
+		return
+	}
+	a.Logf("tagged %s", version)
+
+	if !conf.releaseTagPush {
+		return
+	}
+	if !execCmd(a, "git push origin "+version) {
+		return
+	}
+
+	if !conf.githubRelease {
+		return
+	}
+	writeReleaseNotes(a, conf)
+	args := []string{"gh", "release", "create", version, "--title", version, "--notes-file", filepath.Join(conf.artifactsPath, "release-notes.md")}
+	if files, err := os.ReadDir(filepath.Join(conf.artifactsPath, "release")); err == nil {
+		for _, f := range files {
+			if !f.IsDir() {
+				args = append(args, filepath.Join(conf.artifactsPath, "release", f.Name()))
+			}
+		}
+	}
+	execCmd(a, shellJoin(args))
+}
+
+// nextVersion returns the version following prev given the Conventional Commits types of
+// changes: a major bump for breaking changes, minor for features, and patch otherwise.
+// Before v1, breaking changes bump the minor version.
+func nextVersion(prev string, changes []change) (string, error) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(prev, "v"), "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("previous tag %s is not a semantic version", prev)
+	}
+	var v [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "", fmt.Errorf("previous tag %s is not a semantic version", prev)
+		}
+		v[i] = n
+	}
+
+	bump := 2
+	for _, c := range changes {
+		switch {
+		case c.breaking:
+			bump = 0
+		case c.typ == "feat" && bump > 1:
+			bump = 1
+		}
+	}
+	if bump == 0 && v[0] == 0 {
+		bump = 1
+	}
+	v[bump]++
+	for i := bump + 1; i < 3; i++ {
+		v[i] = 0
+	}
+	return fmt.Sprintf("v%d.%d.%d", v[0], v[1], v[2]), nil
+}
@@ -0,0 +1,83 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	renovateSchema   = "https://docs.renovatebot.com/renovate-schema.json"
+	dependabotSchema = "https://json.schemastore.org/dependabot-2.0.json"
+)
+
+// renovateConfigFiles are the locations Renovate reads its configuration from. JSON5
+// configurations are not listed since they can't be parsed for validation.
+var renovateConfigFiles = []string{
+	"renovate.json",
+	".renovaterc",
+	".renovaterc.json",
+	".github/renovate.json",
+	".gitlab/renovate.json",
+}
+
+var dependabotConfigFiles = []string{".github/dependabot.yml", ".github/dependabot.yaml"}
+
+// defineRenovateTasks defines the lint-renovate task if the repository contains a
+// Renovate or Dependabot configuration.
+func defineRenovateTasks(conf *config) {
+	if len(globFiles(conf, renovateConfigFiles...)) == 0 && len(globFiles(conf, dependabotConfigFiles...)) == 0 {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-renovate",
+		Usage: "Validates Renovate and Dependabot configuration against their schemas.",
+		Action: func(a *goyek.A) {
+			lintDependencyConfig(a, renovateSchema, globFiles(conf, renovateConfigFiles...), false)
+			lintDependencyConfig(a, dependabotSchema, globFiles(conf, dependabotConfigFiles...), true)
+		},
+	}))
+}
+
+// lintDependencyConfig validates the JSON, or YAML if isYAML, files with the schema.
+func lintDependencyConfig(a *goyek.A, url string, files []string, isYAML bool) {
+	a.Helper()
+
+	if len(files) == 0 {
+		return
+	}
+	schema, err := jsonschema.Compile(url)
+	if err != nil {
+		a.Errorf("failed to compile JSON schema %s: %v", url, err)
+		return
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			a.Errorf("failed to read %s: %v", f, err)
+			continue
+		}
+		if isYAML {
+			// Converted to JSON for the types expected by the validator.
+			var y any
+			if err := yaml.Unmarshal(b, &y); err != nil {
+				a.Errorf("%s: %v", f, err)
+				continue
+			}
+			if b, err = json.Marshal(y); err != nil {
+				a.Errorf("%s: %v", f, err)
+				continue
+			}
+		}
+		v, err := decodeJSON(f, b)
+		if err != nil {
+			a.Error(err)
+			continue
+		}
+		validateSchema(a, schema, url, f, v)
+	}
+}
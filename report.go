@@ -0,0 +1,126 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// taskRecord is the result of a task run recorded for reports.
+type taskRecord struct {
+	Name     string         `json:"name"`
+	Status   string         `json:"status"`
+	Start    time.Time      `json:"start"`
+	Duration float64        `json:"durationSeconds"`
+	Outputs  map[string]any `json:"outputs,omitempty"`
+}
+
+// buildReport is the machine-readable report of a build run.
+type buildReport struct {
+	Start    time.Time    `json:"start"`
+	Duration float64      `json:"durationSeconds"`
+	Status   string       `json:"status"`
+	Tasks    []taskRecord `json:"tasks"`
+}
+
+// taskRecorder records the results of tasks as they finish, along with any outputs
+// reported by their actions.
+type taskRecorder struct {
+	start time.Time
+
+	mu      sync.Mutex
+	tasks   []taskRecord
+	outputs map[string]map[string]any
+}
+
+var recorder = &taskRecorder{
+	start:   time.Now(),
+	outputs: map[string]map[string]any{},
+}
+
+// recordOutput records a key output of the running task, e.g. coverage, for reports.
+func recordOutput(a *goyek.A, key string, value any) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	outputs, ok := recorder.outputs[a.Name()]
+	if !ok {
+		outputs = map[string]any{}
+		recorder.outputs[a.Name()] = outputs
+	}
+	outputs[key] = value
+}
+
+// recordTasks returns a middleware recording the result of each task. If a build
+// report is enabled, it is rewritten after every task so it is complete even if
+// the run stops on a failure. Slow tasks are warned about as they finish, and the
+// timing report is printed after the last task of the run.
+func recordTasks(conf *config) goyek.Middleware {
+	return func(next goyek.Runner) goyek.Runner {
+		return func(in goyek.Input) goyek.Result {
+			start := time.Now()
+			res := next(in)
+			d := time.Since(start)
+			// Written directly to the flow output since the task output is discarded
+			// for passing tasks unless running verbosely.
+			warnSlowTask(conf, goyek.Output(), in.TaskName, d)
+			finished := conf.timingReport && runFinished(in.TaskName, res)
+
+			recorder.mu.Lock()
+			recorder.tasks = append(recorder.tasks, taskRecord{
+				Name:     in.TaskName,
+				Status:   strings.ToLower(res.Status.String()),
+				Start:    start,
+				Duration: d.Seconds(),
+				Outputs:  recorder.outputs[in.TaskName],
+			})
+			recorder.mu.Unlock()
+
+			if finished {
+				reportTimings(conf, goyek.Output())
+			}
+
+			if conf.buildReport {
+				if err := writeBuildReport(conf); err != nil {
+					fmt.Fprintf(in.Output, "failed to write build report: %v\n", err)
+				}
+			}
+			return res
+		}
+	}
+}
+
+// writeBuildReport writes the tasks recorded so far to build-report.json in the
+// artifacts directory.
+func writeBuildReport(conf *config) error {
+	recorder.mu.Lock()
+	report := buildReport{
+		Start:    recorder.start,
+		Duration: time.Since(recorder.start).Seconds(),
+		Status:   "pass",
+		Tasks:    append([]taskRecord(nil), recorder.tasks...),
+	}
+	recorder.mu.Unlock()
+
+	for _, t := range report.Tasks {
+		if t.Status == "fail" {
+			report.Status = "fail"
+			break
+		}
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(conf.artifactsPath, "build-report.json"), append(b, '\n'), 0o644)
+}
@@ -0,0 +1,59 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goyek/goyek/v2"
+)
+
+const (
+	// SBOMCycloneDX is the CycloneDX SBOM format.
+	SBOMCycloneDX = "cyclonedx"
+	// SBOMSPDX is the SPDX SBOM format.
+	SBOMSPDX = "spdx"
+)
+
+func (c *config) sbomPath() string {
+	return filepath.Join(c.artifactsPath, "sbom")
+}
+
+func (c *config) formatsSBOM() []string {
+	if len(c.sbomFormats) == 0 {
+		return []string{SBOMCycloneDX}
+	}
+	return c.sbomFormats
+}
+
+// sbomFileName returns the name of the SBOM file generated for the format.
+func sbomFileName(format string) string {
+	if format == SBOMSPDX {
+		return "sbom.spdx.json"
+	}
+	return "sbom.cdx.json"
+}
+
+// generateSBOM writes SBOM documents for the module in each configured format to the
+// sbom artifacts directory.
+func generateSBOM(a *goyek.A, conf *config) {
+	a.Helper()
+
+	if err := os.MkdirAll(conf.sbomPath(), 0o755); err != nil {
+		a.Fatalf("failed to create SBOM directory: %v", err)
+	}
+
+	for _, format := range conf.formatsSBOM() {
+		out := filepath.Join(conf.sbomPath(), sbomFileName(format))
+		switch format {
+		case SBOMCycloneDX:
+			execCmd(a, fmt.Sprintf("go run github.com/CycloneDX/cyclonedx-gomod/cmd/cyclonedx-gomod@%s mod -licenses -json -output %s",
+				conf.version("cyclonedx-gomod", verCycloneDXGoMod), shellJoin([]string{out})))
+		case SBOMSPDX:
+			execCmd(a, fmt.Sprintf("go run github.com/anchore/syft/cmd/syft@%s scan dir:. -o %s",
+				conf.version("syft", verSyft), shellJoin([]string{"spdx-json=" + out})))
+		default:
+			a.Errorf("unknown SBOM format %q", format)
+		}
+	}
+}
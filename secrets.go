@@ -0,0 +1,51 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defaultSecretsBaseline is the gitleaks baseline used if present and no other is
+// configured.
+const defaultSecretsBaseline = ".gitleaks-baseline.json"
+
+// defineSecretsTask defines the lint-secrets task.
+func defineSecretsTask(conf *config) {
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-secrets",
+		Usage: "Scans for committed credentials with gitleaks.",
+		Action: func(a *goyek.A) {
+			lintSecrets(a, conf)
+		},
+	}))
+}
+
+// lintSecrets scans the working tree, and the configured depth of git history, with
+// gitleaks, writing reports into the artifacts directory.
+func lintSecrets(a *goyek.A, conf *config) {
+	a.Helper()
+
+	if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+		a.Fatalf("failed to create out directory: %v", err)
+	}
+
+	gitleaks := fmt.Sprintf("go run github.com/zricethezav/gitleaks/v8@%s detect --source . --redact --no-banner", conf.version("gitleaks", verGitleaks))
+	baseline := conf.secretsBaseline
+	if baseline == "" && fileExists(defaultSecretsBaseline) {
+		baseline = defaultSecretsBaseline
+	}
+	if baseline != "" {
+		gitleaks += " --baseline-path " + shellJoin([]string{baseline})
+	}
+
+	report := filepath.Join(conf.artifactsPath, "gitleaks.json")
+	execCmd(a, fmt.Sprintf("%s --no-git --report-path %s", gitleaks, shellJoin([]string{report})))
+
+	if conf.secretsHistoryDepth > 0 && inGitRepo() {
+		report := filepath.Join(conf.artifactsPath, "gitleaks-history.json")
+		execCmd(a, fmt.Sprintf("%s --log-opts=--max-count=%d --report-path %s", gitleaks, conf.secretsHistoryDepth, shellJoin([]string{report})))
+	}
+}
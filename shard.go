@@ -0,0 +1,125 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// testShard returns the index and total number of test shards, from TestShard or the
+// TEST_SHARD_INDEX and TEST_TOTAL_SHARDS environment variables. total is 0 if tests
+// are not sharded.
+func (c *config) testShard() (index int, total int) {
+	if c.testShardTotal > 0 {
+		return c.testShardIndex, c.testShardTotal
+	}
+	total, err := strconv.Atoi(os.Getenv("TEST_TOTAL_SHARDS"))
+	if err != nil || total <= 0 {
+		return 0, 0
+	}
+	index, err = strconv.Atoi(os.Getenv("TEST_SHARD_INDEX"))
+	if err != nil {
+		return 0, 0
+	}
+	return index, total
+}
+
+func (c *config) testTimingsPath() string {
+	return filepath.Join(c.artifactsPath, "test-timings.json")
+}
+
+// shardPackages returns the packages to test in the current shard. Packages are
+// assigned to shards to balance the total duration using timings of previous runs,
+// so all shards must have the same timings file for a deterministic split. Packages of
+// each module, in dir, are split separately.
+func shardPackages(a *goyek.A, conf *config, dir string, tags string, index int, total int) ([]string, bool) {
+	a.Helper()
+
+	listCmd := "go list"
+	if tags != "" {
+		listCmd += " -tags=" + tags
+	}
+	var list strings.Builder
+	if !execCmd(a, listCmd+" "+strings.Join(conf.packages(), " "), moduleOpts(dir, []cmd.Option{cmd.Stdout(&list)})...) {
+		return nil, false
+	}
+	pkgs := strings.Fields(list.String())
+
+	timings := readTestTimings(conf)
+	// Packages without timings are assumed to take the average duration.
+	var sum float64
+	for _, t := range timings {
+		sum += t
+	}
+	avg := 1.0
+	if len(timings) > 0 && sum > 0 {
+		avg = sum / float64(len(timings))
+	}
+	weight := func(pkg string) float64 {
+		if t, ok := timings[pkg]; ok {
+			return t
+		}
+		return avg
+	}
+
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		wi, wj := weight(pkgs[i]), weight(pkgs[j])
+		if wi != wj {
+			return wi > wj
+		}
+		return pkgs[i] < pkgs[j]
+	})
+
+	loads := make([]float64, total)
+	var shard []string
+	for _, pkg := range pkgs {
+		lightest := 0
+		for i := range loads {
+			if loads[i] < loads[lightest] {
+				lightest = i
+			}
+		}
+		loads[lightest] += weight(pkg)
+		if lightest == index {
+			shard = append(shard, pkg)
+		}
+	}
+	sort.Strings(shard)
+	return shard, true
+}
+
+func readTestTimings(conf *config) map[string]float64 {
+	b, err := os.ReadFile(conf.testTimingsPath())
+	if err != nil {
+		return nil
+	}
+	var timings map[string]float64
+	if err := json.Unmarshal(b, &timings); err != nil {
+		return nil
+	}
+	return timings
+}
+
+// writeTestTimings merges the package durations from test events into the timings file.
+func writeTestTimings(conf *config, events []testEvent) error {
+	timings := readTestTimings(conf)
+	if timings == nil {
+		timings = map[string]float64{}
+	}
+	for _, ev := range events {
+		if ev.Test == "" && (ev.Action == "pass" || ev.Action == "fail") {
+			timings[ev.Package] = ev.Elapsed
+		}
+	}
+	b, err := json.MarshalIndent(timings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(conf.testTimingsPath(), b, 0o644)
+}
@@ -0,0 +1,36 @@
+package build
+
+import (
+	"fmt"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineShellTasks defines tasks for shell scripts if the repository contains any.
+func defineShellTasks(conf *config) {
+	if len(findFiles(conf, "*.sh")) == 0 {
+		return
+	}
+
+	RegisterFormatTask(goyek.Define(goyek.Task{
+		Name:  "format-shell",
+		Usage: "Formats shell scripts.",
+		Action: func(a *goyek.A) {
+			files := findFiles(conf, "*.sh")
+			shfmt := fmt.Sprintf("go run mvdan.cc/sh/v3/cmd/shfmt@%s", conf.version("shfmt", verShfmt))
+			if conf.formatCheckOnly() {
+				execFiles(a, shfmt+" -d", files)
+				return
+			}
+			execFiles(a, shfmt+" -w", files)
+		},
+	}))
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-shell",
+		Usage: "Lints shell scripts.",
+		Action: func(a *goyek.A) {
+			execFiles(a, fmt.Sprintf("go run github.com/wasilibs/go-shellcheck/cmd/shellcheck@%s", conf.version("shellcheck", verShellcheck)), findFiles(conf, "*.sh"))
+		},
+	}))
+}
@@ -0,0 +1,84 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// signedImages returns the images to sign, those pushed by docker-ko.
+func signedImages(conf *config) []string {
+	if !conf.koPush {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(conf.artifactsPath, "docker", "ko-images.txt"))
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(content))
+}
+
+// signedFiles returns the release files to sign, excluding existing signature bundles.
+func signedFiles(a *goyek.A, conf *config) []string {
+	a.Helper()
+
+	releaseDir := filepath.Join(conf.artifactsPath, "release")
+	entries, err := os.ReadDir(releaseDir)
+	if err != nil {
+		a.Fatalf("failed to read release directory, run release first: %v", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), ".bundle") {
+			continue
+		}
+		files = append(files, filepath.Join(releaseDir, e.Name()))
+	}
+	return files
+}
+
+func (c *config) cosign() string {
+	return fmt.Sprintf("go run github.com/sigstore/cosign/v2/cmd/cosign@%s", c.version("cosign", verCosign))
+}
+
+// signRelease signs the release files, writing a signature bundle next to each, and any
+// pushed images with cosign.
+func signRelease(a *goyek.A, conf *config) {
+	a.Helper()
+
+	keyFlag := ""
+	if conf.cosignKey != "" {
+		keyFlag = " --key " + shellJoin([]string{conf.cosignKey})
+	}
+	for _, file := range signedFiles(a, conf) {
+		execCmd(a, fmt.Sprintf("%s sign-blob --yes%s --bundle %s %s", conf.cosign(), keyFlag, shellJoin([]string{file + ".bundle"}), shellJoin([]string{file})))
+	}
+	for _, image := range signedImages(conf) {
+		execCmd(a, fmt.Sprintf("%s sign --yes%s %s", conf.cosign(), keyFlag, image))
+	}
+}
+
+// verifySignatures verifies the signatures created by release-sign, with the public key
+// for key-based signing or the configured certificate identity for keyless signing.
+func verifySignatures(a *goyek.A, conf *config) {
+	a.Helper()
+
+	var verifyFlags string
+	switch {
+	case conf.cosignPublicKey != "":
+		verifyFlags = " --key " + shellJoin([]string{conf.cosignPublicKey})
+	case conf.cosignIdentity != "":
+		verifyFlags = fmt.Sprintf(" --certificate-identity %s --certificate-oidc-issuer %s", shellJoin([]string{conf.cosignIdentity}), shellJoin([]string{conf.cosignIssuer}))
+	default:
+		a.Fatal("no public key or certificate identity configured for verifying signatures")
+	}
+	for _, file := range signedFiles(a, conf) {
+		execCmd(a, fmt.Sprintf("%s verify-blob%s --bundle %s %s", conf.cosign(), verifyFlags, shellJoin([]string{file + ".bundle"}), shellJoin([]string{file})))
+	}
+	for _, image := range signedImages(conf) {
+		execCmd(a, fmt.Sprintf("%s verify%s %s", conf.cosign(), verifyFlags, image))
+	}
+}
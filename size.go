@@ -0,0 +1,176 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// reportSizes writes a breakdown of the size of each binary built by build-go by
+// package into the artifacts directory, along with sizes.json containing the total size
+// of each binary which can be used as a baseline for later builds.
+func reportSizes(a *goyek.A, conf *config) {
+	a.Helper()
+
+	sizeDir := filepath.Join(conf.artifactsPath, "size")
+	if dryRun() {
+		// The binaries to report on are not built with -dry-run.
+		a.Log("Write (dry run): ", sizeDir)
+		return
+	}
+	if err := os.MkdirAll(sizeDir, 0o755); err != nil {
+		a.Fatalf("failed to create size directory: %v", err)
+	}
+
+	sizes := map[string]int64{}
+	for _, target := range conf.targets() {
+		goos, goarch, _ := strings.Cut(target, "/")
+		platform := goos + "_" + goarch
+		binDir := filepath.Join(conf.artifactsPath, "bin", platform)
+		files, err := os.ReadDir(binDir)
+		if err != nil {
+			a.Errorf("failed to read binaries for %s: %v", target, err)
+			continue
+		}
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+			info, err := f.Info()
+			if err != nil {
+				a.Errorf("failed to stat binary %s: %v", f.Name(), err)
+				continue
+			}
+			name := platform + "/" + f.Name()
+			sizes[name] = info.Size()
+
+			var nm strings.Builder
+			if !execCmd(a, "go tool nm -size "+shellJoin([]string{filepath.Join(binDir, f.Name())}), cmd.Stdout(&nm)) {
+				continue
+			}
+			report := filepath.Join(sizeDir, platform+"_"+strings.TrimSuffix(f.Name(), ".exe")+".txt")
+			if err := os.WriteFile(report, []byte(formatSizeBreakdown(info.Size(), nm.String())), 0o644); err != nil {
+				a.Errorf("failed to write size report: %v", err)
+			}
+			a.Logf("%s: %s", name, formatBytes(info.Size()))
+		}
+	}
+
+	out, err := json.MarshalIndent(sizes, "", "  ")
+	if err != nil {
+		a.Fatalf("failed to encode sizes: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sizeDir, "sizes.json"), append(out, '\n'), 0o644); err != nil {
+		a.Errorf("failed to write sizes: %v", err)
+	}
+
+	checkSizes(a, conf, sizes)
+}
+
+// checkSizes fails the task if any binary grew by more than the configured threshold
+// compared to the baseline.
+func checkSizes(a *goyek.A, conf *config, sizes map[string]int64) {
+	a.Helper()
+
+	if conf.sizeBaseline == "" {
+		return
+	}
+	content, err := os.ReadFile(conf.sizeBaseline)
+	if err != nil {
+		a.Errorf("failed to read size baseline: %v", err)
+		return
+	}
+	var baseline map[string]int64
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		a.Errorf("failed to parse size baseline: %v", err)
+		return
+	}
+
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		base, ok := baseline[name]
+		if !ok || base == 0 {
+			continue
+		}
+		pct := float64(sizes[name]-base) * 100 / float64(base)
+		a.Logf("%s: %+.1f%% compared to baseline", name, pct)
+		if conf.sizeRegressionThreshold > 0 && pct > conf.sizeRegressionThreshold {
+			a.Errorf("binary %s grew by %.1f%% to %s, more than threshold %.1f%%", name, pct, formatBytes(sizes[name]), conf.sizeRegressionThreshold)
+		}
+	}
+}
+
+// formatSizeBreakdown sums the sizes of symbols reported by go tool nm by package,
+// listing packages from largest to smallest.
+func formatSizeBreakdown(total int64, nm string) string {
+	pkgs := map[string]int64{}
+	var symbols int64
+	for _, line := range strings.Split(nm, "\n") {
+		// Format is address size type name, with name possibly containing spaces.
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		pkgs[symbolPackage(strings.Join(fields[3:], " "))] += size
+		symbols += size
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for name := range pkgs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if pkgs[names[i]] != pkgs[names[j]] {
+			return pkgs[names[i]] > pkgs[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%10s  total\n", formatBytes(total))
+	fmt.Fprintf(&sb, "%10s  symbols\n", formatBytes(symbols))
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%10s  %5.1f%%  %s\n", formatBytes(pkgs[name]), float64(pkgs[name])*100/float64(total), name)
+	}
+	return sb.String()
+}
+
+// symbolPackage returns the package path of a symbol name, e.g. github.com/a/b for
+// github.com/a/b.(*T).Method. Runtime-generated symbols like type:* are grouped by
+// their prefix.
+func symbolPackage(sym string) string {
+	if prefix, _, ok := strings.Cut(sym, ":"); ok && !strings.Contains(prefix, ".") {
+		return prefix + ":"
+	}
+	start := strings.LastIndex(sym, "/") + 1
+	if dot := strings.Index(sym[start:], "."); dot >= 0 {
+		return sym[:start+dot]
+	}
+	return sym
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,212 @@
+package build
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// defaultSpellingDictionary is the project dictionary used if it exists when none is
+// set with SpellingDictionary.
+const defaultSpellingDictionary = "project-words.txt"
+
+// cspellConfigFiles are the names of cspell configuration files cspell uses
+// automatically from the working directory.
+var cspellConfigFiles = []string{
+	"cspell.json",
+	".cspell.json",
+	"cspell.config.json",
+	"cspell.config.yaml",
+	"cspell.config.yml",
+	"cspell.yaml",
+	"cspell.yml",
+}
+
+// spellingDictionary returns the path of the project dictionary, or empty if there is
+// none.
+func (c *config) spellingDictionary() string {
+	if c.spellingDictPath != "" {
+		return c.spellingDictPath
+	}
+	if _, err := os.Stat(defaultSpellingDictionary); err == nil {
+		return defaultSpellingDictionary
+	}
+	return ""
+}
+
+// defineSpellingTasks defines the lint-spelling task if enabled with Spelling and the
+// repository contains Go or Markdown files.
+func defineSpellingTasks(conf *config) {
+	if !conf.spelling || (len(findFiles(conf, "*.go")) == 0 && len(globFiles(conf, conf.markdownPatterns()...)) == 0) {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-spelling",
+		Usage: "Checks spelling in code and Markdown files.",
+		Action: func(a *goyek.A) {
+			lintSpelling(a, conf)
+		},
+	}))
+}
+
+// lintSpelling checks Go and Markdown files for common misspellings with misspell and,
+// if enabled with CSpell, Markdown files for unknown words with cspell. Words in the
+// project dictionary are accepted by both. Unless FailOnSpelling is set, findings are
+// only reported, while failing to run a checker always fails the task.
+func lintSpelling(a *goyek.A, conf *config) {
+	a.Helper()
+
+	words, err := readDictionary(conf.spellingDictionary())
+	if err != nil {
+		a.Fatalf("failed to read spelling dictionary: %v", err)
+	}
+
+	var out strings.Builder
+	ok := true
+
+	misspell := fmt.Sprintf("go run github.com/golangci/misspell/cmd/misspell@%s -error", conf.version("misspell", verMisspell))
+	if len(words) > 0 {
+		misspell += " -i " + shellQuote(strings.Join(words, ","))
+	}
+	files := append(findFiles(conf, "*.go"), globFiles(conf, conf.markdownPatterns()...)...)
+	if !trySpelling(a, misspell, files, &out) {
+		ok = false
+	}
+
+	if md := globFiles(conf, conf.markdownPatterns()...); conf.cspell && len(md) > 0 {
+		cspell, err := cspellCommand(conf)
+		if err != nil {
+			a.Fatalf("failed to write cspell config: %v", err)
+		}
+		if !trySpelling(a, cspell, md, &out) {
+			ok = false
+		}
+	}
+
+	if ok || a.Failed() {
+		return
+	}
+	if conf.spellingFatal {
+		a.Error("spelling errors found, add correct words to the project dictionary")
+		return
+	}
+	// Output of passing tasks is hidden without -v, so findings are written directly.
+	if !verbose() {
+		fmt.Fprintf(goyek.Output(), "WARNING: lint-spelling found possible misspellings:\n%s", out.String())
+	}
+	a.Log("WARNING: spelling errors found, add correct words to the project dictionary")
+}
+
+// trySpelling runs the spell checker with the files, also collecting the findings it
+// writes to stdout, and returns whether it found none. If the checker fails without
+// reporting findings, e.g. because Docker is not available, the task fails since the
+// files were not checked.
+func trySpelling(a *goyek.A, cmdLine string, files []string, out io.Writer) bool {
+	a.Helper()
+
+	ok := true
+	for i, f := range files {
+		files[i] = filepath.ToSlash(f)
+	}
+	for _, chunk := range chunkFiles(files) {
+		var findings strings.Builder
+		err := tryExec(a, cmdLine+" "+shellJoin(chunk), cmd.Stdout(io.MultiWriter(a.Output(), out, &findings)))
+		switch {
+		case err == nil:
+		case findings.Len() == 0:
+			a.Errorf("failed to check spelling: %v", err)
+			ok = false
+		default:
+			ok = false
+		}
+	}
+	return ok
+}
+
+// cspellCommand returns the command line to run cspell. cspell is not distributed as
+// a Go program so it is run with its official image. If the repository has no cspell
+// configuration, one using the project dictionary is written to the artifacts
+// directory. Both are mounted into the container next to each other since either may
+// be outside the mounted working directory.
+func cspellCommand(conf *config) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	mounts := []string{"-v", wd + ":/work"}
+	var args string
+	if dict := conf.spellingDictionary(); dict != "" && !hasCSpellConfig() {
+		dictPath, err := filepath.Abs(dict)
+		if err != nil {
+			return "", err
+		}
+		cfgPath, err := filepath.Abs(filepath.Join(conf.artifactsPath, "cspell.json"))
+		if err != nil {
+			return "", err
+		}
+		// Paths in the config are relative to the config file.
+		cfg, err := json.MarshalIndent(map[string]any{
+			"version": "0.2",
+			"dictionaryDefinitions": []map[string]any{
+				{"name": "project", "path": "project-words.txt", "addWords": true},
+			},
+			"dictionaries": []string{"project"},
+		}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if !dryRun() {
+			if err := os.MkdirAll(filepath.Dir(cfgPath), 0o755); err != nil {
+				return "", err
+			}
+			if err := os.WriteFile(cfgPath, cfg, 0o644); err != nil {
+				return "", err
+			}
+		}
+		mounts = append(mounts, "-v", cfgPath+":/cspell/cspell.json", "-v", dictPath+":/cspell/project-words.txt")
+		args = " --config /cspell/cspell.json"
+	}
+	return fmt.Sprintf("docker run --rm %s -w /work ghcr.io/streetsidesoftware/cspell:%s --no-progress --no-summary --no-must-find-files%s", shellJoin(mounts), conf.version("cspell", verCSpell), args), nil
+}
+
+// hasCSpellConfig returns whether the working directory has a cspell configuration.
+func hasCSpellConfig() bool {
+	for _, f := range cspellConfigFiles {
+		if _, err := os.Stat(f); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// readDictionary reads the words of the dictionary, one per line, ignoring blank lines
+// and lines starting with #.
+func readDictionary(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		w := strings.TrimSpace(s.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		words = append(words, w)
+	}
+	return words, s.Err()
+}
@@ -1,8 +1,14 @@
 package build
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/goyek/goyek/v2"
 	"github.com/goyek/x/cmd"
@@ -10,43 +16,357 @@ import (
 
 // DefineTasks defines common tasks for Go projects.
 func DefineTasks(opts ...Option) {
-	var conf config
+	conf := config{
+		artifactsPath:   "out",
+		flakyTestBudget: -1,
+	}
 	for _, o := range opts {
 		o.apply(&conf)
 	}
+	if err := loadConfigFile(&conf); err != nil {
+		panic(err)
+	}
+	applyCIProfile(&conf)
+	commands.limit = conf.maxParallel
+	// Middlewares registered first run innermost, so tasks are recorded with their final
+	// result, including timeouts and tasks skipped when keeping going.
+	goyek.Use(enforceTimeouts(&conf))
+	goyek.Use(skipFailedDeps)
+	goyek.Use(recordTasks(&conf))
+	goyek.Use(captureLogs(&conf))
+
+	formatGo := goyek.Define(goyek.Task{
+		Name:  "format-go",
+		Usage: "Formats Go code.",
+		Action: func(a *goyek.A) {
+			importSecs := "-s standard -s default"
+			for _, prefix := range conf.localPackagePrefixes {
+				importSecs += fmt.Sprintf(` -s "prefix(%s)"`, prefix)
+			}
+
+			if conf.formatCheckOnly() {
+				execNoOutput(a, fmt.Sprintf("go run mvdan.cc/gofumpt@%s -l .", conf.version("gofumpt", verGoFumpt)), "files are not formatted with gofumpt")
+				execNoOutput(a, fmt.Sprintf("go run github.com/daixiang0/gci@%s diff %s .", conf.version("gci", verGci), importSecs), "imports are not formatted with gci")
+				return
+			}
+
+			execCmd(a, fmt.Sprintf("go run mvdan.cc/gofumpt@%s -l -w .", conf.version("gofumpt", verGoFumpt)))
+			execCmd(a, fmt.Sprintf("go run github.com/daixiang0/gci@%s write %s .", conf.version("gci", verGci), importSecs))
+		},
+	})
 
 	goyek.Define(goyek.Task{
 		Name:  "format",
 		Usage: "Formats the code.",
+		Deps:  goyek.Deps{formatGo},
+	})
+
+	lintGo := goyek.Define(goyek.Task{
+		Name:  "lint-go",
+		Usage: "Lints Go code.",
 		Action: func(a *goyek.A) {
-			cmd.Exec(a, fmt.Sprintf("go run mvdan.cc/gofumpt@%s -l -w .", verGoFumpt))
+			runGolangCILint(a, &conf)
+		},
+	})
 
-			importSecs := "-s standard -s default"
-			for _, prefix := range conf.localPackagePrefixes {
-				importSecs += fmt.Sprintf(` -s "prefix(%s)"`, prefix)
+	lintVuln := goyek.Define(goyek.Task{
+		Name:  "lint-vuln",
+		Usage: "Checks dependencies for known vulnerabilities.",
+		Action: func(a *goyek.A) {
+			cmdLine := fmt.Sprintf("go run golang.org/x/vuln/cmd/govulncheck@%s ./...", conf.version("govulncheck", verGoVulnCheck))
+			if conf.lintSARIF {
+				// govulncheck only writes SARIF to stdout, without human-readable output or a
+				// failing exit code, so it is run separately to generate the report.
+				if !mkdirSARIF(a, &conf) {
+					return
+				}
+				var sarif strings.Builder
+				sarifCmd := fmt.Sprintf("go run golang.org/x/vuln/cmd/govulncheck@%s -format=sarif ./...", conf.version("govulncheck", verGoVulnCheck))
+				if !execCmd(a, sarifCmd, cmd.Stdout(&sarif)) {
+					return
+				}
+				if err := os.WriteFile(filepath.Join(conf.sarifPath(), "govulncheck.sarif"), []byte(sarif.String()), 0o644); err != nil {
+					a.Errorf("failed to write SARIF report: %v", err)
+					return
+				}
+			}
+			if !conf.vulnCheckWarnOnly {
+				execCmd(a, cmdLine)
+				return
 			}
+			if err := tryExec(a, cmdLine); err != nil {
+				a.Logf("WARNING: govulncheck reported issues: %v", err)
+			}
+		},
+	})
+
+	lintGoMod := goyek.Define(goyek.Task{
+		Name:  "lint-go-mod",
+		Usage: "Verifies go.mod and go.sum are tidy for all modules.",
+		Action: func(a *goyek.A) {
+			dirs, err := goModules(&conf)
+			if err != nil {
+				a.Fatalf("failed to find Go modules: %v", err)
+			}
+			for _, dir := range dirs {
+				checkModTidy(a, dir)
+			}
+		},
+	})
 
-			cmd.Exec(a, fmt.Sprintf("go run github.com/daixiang0/gci@%s write %s .", verGci, importSecs))
+	lintVet := goyek.Define(goyek.Task{
+		Name:  "lint-vet",
+		Usage: "Runs go vet, including any custom vet tools.",
+		Action: func(a *goyek.A) {
+			runVet(a, &conf)
 		},
 	})
 
 	lint := goyek.Define(goyek.Task{
 		Name:  "lint",
 		Usage: "Lints the code.",
+		Deps:  goyek.Deps{lintGo, lintGoMod, lintVet, lintVuln},
+	})
+
+	testGo := goyek.Define(goyek.Task{
+		Name:  "test-go",
+		Usage: "Runs Go unit tests.",
+		Action: func(a *goyek.A) {
+			runTests(a, &conf)
+		},
+	})
+
+	testIntegration := goyek.Define(goyek.Task{
+		Name:  "test-integration",
+		Usage: "Runs Go integration tests.",
 		Action: func(a *goyek.A) {
-			cmd.Exec(a, fmt.Sprintf("go run github.com/golangci/golangci-lint/cmd/golangci-lint@%s run --timeout=20m", verGolangCILint))
+			withCompose(a, &conf, func() bool {
+				return runGoTest(a, &conf, testRun{
+					name:    "integration",
+					tags:    conf.integrationTag(),
+					timeout: conf.integrationTestTimeout(),
+				})
+			})
 		},
 	})
 
+	goyek.Define(goyek.Task{
+		Name:  "coverage-merge",
+		Usage: "Merges coverage profiles of test tasks run before it into one profile.",
+		Action: func(a *goyek.A) {
+			mergeCoverage(a, &conf)
+		},
+	})
+
+	if conf.coverageService != "" {
+		goyek.Define(goyek.Task{
+			Name:  "coverage-upload",
+			Usage: "Uploads coverage of test tasks run before it to a coverage service.",
+			Action: func(a *goyek.A) {
+				uploadCoverage(a, &conf)
+			},
+		})
+	}
+
+	testDeps := goyek.Deps{testGo}
+	if conf.integrationTestsInCheck {
+		testDeps = append(testDeps, testIntegration)
+	}
 	test := goyek.Define(goyek.Task{
 		Name:  "test",
-		Usage: "Runs unit tests.",
+		Usage: "Runs tests.",
+		Deps:  testDeps,
+	})
+
+	goyek.Define(goyek.Task{
+		Name:  "bench",
+		Usage: "Runs benchmarks.",
 		Action: func(a *goyek.A) {
-			if err := os.MkdirAll("out", 0o755); err != nil {
-				a.Errorf("failed to create out directory: %v", err)
-				return
+			runBenchmarks(a, &conf)
+		},
+	})
+
+	goyek.Define(goyek.Task{
+		Name:  "fuzz",
+		Usage: "Runs fuzz tests.",
+		Action: func(a *goyek.A) {
+			runFuzz(a, &conf)
+		},
+	})
+
+	generateGo := goyek.Define(goyek.Task{
+		Name:  "generate-go",
+		Usage: "Runs go generate.",
+		Action: func(a *goyek.A) {
+			execCmd(a, "go generate ./...")
+		},
+	})
+
+	generate := goyek.Define(goyek.Task{
+		Name:  "generate",
+		Usage: "Generates code.",
+		Deps:  goyek.Deps{generateGo},
+	})
+
+	if inGitRepo() {
+		// Files already changed before generate runs are recorded so generate-check only
+		// fails for files generate changes, not unrelated local changes. The task has no
+		// usage to keep it out of the task list.
+		var before map[string]string
+		snapshot := goyek.Define(goyek.Task{
+			Name: "generate-check-snapshot",
+			Action: func(a *goyek.A) {
+				var err error
+				if before, err = gitDirtyFiles(); err != nil {
+					a.Fatal(err)
+				}
+			},
+		})
+		goyek.Define(goyek.Task{
+			Name:  "generate-check",
+			Usage: "Verifies generated code is up to date.",
+			Deps:  goyek.Deps{snapshot, generate},
+			Action: func(a *goyek.A) {
+				after, err := gitDirtyFiles()
+				if err != nil {
+					a.Fatal(err)
+				}
+				var changed []string
+				for path, hash := range after {
+					if prev, ok := before[path]; !ok || prev != hash {
+						changed = append(changed, path)
+					}
+				}
+				for path := range before {
+					if _, ok := after[path]; !ok {
+						changed = append(changed, path)
+					}
+				}
+				if len(changed) > 0 {
+					sort.Strings(changed)
+					a.Errorf("generated code is not up to date, run the generate task and commit the changes:\n%s", strings.Join(changed, "\n"))
+				}
+			},
+		})
+	}
+
+	sbom := goyek.Define(goyek.Task{
+		Name:  "sbom",
+		Usage: "Generates software bills of materials for the module.",
+		Action: func(a *goyek.A) {
+			generateSBOM(a, &conf)
+		},
+	})
+
+	if dirExists("cmd") {
+		buildGo := goyek.Define(goyek.Task{
+			Name:  "build-go",
+			Usage: "Builds binaries under ./cmd for all configured targets.",
+			Action: func(a *goyek.A) {
+				buildBinaries(a, &conf)
+			},
+		})
+
+		goyek.Define(goyek.Task{
+			Name:  "size-report",
+			Usage: "Reports the size of built binaries by package, checking for growth against any baseline.",
+			Deps:  goyek.Deps{buildGo},
+			Action: func(a *goyek.A) {
+				reportSizes(a, &conf)
+			},
+		})
+
+		if len(conf.linuxPackageFormats) > 0 {
+			goyek.Define(goyek.Task{
+				Name:  "package-linux",
+				Usage: "Packages built linux binaries with nfpm into the packages artifacts directory.",
+				Deps:  goyek.Deps{buildGo},
+				Action: func(a *goyek.A) {
+					packageLinux(a, &conf)
+				},
+			})
+		}
+
+		releaseDeps := goyek.Deps{buildGo}
+		if conf.releaseSBOM {
+			releaseDeps = append(releaseDeps, sbom)
+		}
+		releaseTask := goyek.Define(goyek.Task{
+			Name:  "release",
+			Usage: "Packages built binaries into versioned archives with checksums under the release artifacts directory.",
+			Deps:  releaseDeps,
+			Action: func(a *goyek.A) {
+				release(a, &conf)
+			},
+		})
+
+		signDeps := goyek.Deps{releaseTask}
+		if conf.slsaProvenance {
+			// Signing depends on provenance so the statement is signed too.
+			signDeps = append(signDeps, goyek.Define(goyek.Task{
+				Name:  "release-provenance",
+				Usage: "Writes a SLSA provenance statement for release files into the release artifacts directory.",
+				Deps:  goyek.Deps{releaseTask},
+				Action: func(a *goyek.A) {
+					writeProvenance(a, &conf)
+				},
+			}))
+		}
+
+		if conf.cosignSigning {
+			goyek.Define(goyek.Task{
+				Name:  "release-sign",
+				Usage: "Signs release files and pushed images with cosign.",
+				Deps:  signDeps,
+				Action: func(a *goyek.A) {
+					signRelease(a, &conf)
+				},
+			})
+
+			goyek.Define(goyek.Task{
+				Name:  "verify-signatures",
+				Usage: "Verifies signatures of release files and pushed images created by release-sign.",
+				Action: func(a *goyek.A) {
+					verifySignatures(a, &conf)
+				},
+			})
+		}
+
+		if conf.brew {
+			goyek.Define(goyek.Task{
+				Name:  "release-brew",
+				Usage: "Renders a Homebrew formula for the release archives, pushing it to any configured tap.",
+				Deps:  goyek.Deps{releaseTask},
+				Action: func(a *goyek.A) {
+					releaseBrew(a, &conf)
+				},
+			})
+		}
+	}
+
+	clean := goyek.Define(goyek.Task{
+		Name:  "clean",
+		Usage: "Deletes the artifacts directory and any other configured paths.",
+		Action: func(a *goyek.A) {
+			for _, path := range append([]string{conf.artifactsPath}, conf.cleanPaths...) {
+				if dryRun() {
+					a.Log("Delete (dry run): ", path)
+					continue
+				}
+				if err := os.RemoveAll(path); err != nil {
+					a.Errorf("failed to delete %s: %v", path, err)
+				}
 			}
-			cmd.Exec(a, "go test -coverprofile=out/coverage.txt -covermode=atomic -v -timeout=20m ./...")
+		},
+	})
+
+	goyek.Define(goyek.Task{
+		Name:  "distclean",
+		Usage: "Runs clean and also clears caches of Go tests and golangci-lint.",
+		Deps:  goyek.Deps{clean},
+		Action: func(a *goyek.A) {
+			execCmd(a, "go clean -testcache -fuzzcache")
+			execCmd(a, fmt.Sprintf("go run github.com/golangci/golangci-lint/cmd/golangci-lint@%s cache clean", conf.version("golangci-lint", verGolangCILint)))
 		},
 	})
 
@@ -55,10 +375,375 @@ func DefineTasks(opts ...Option) {
 		Usage: "Runs all checks.",
 		Deps:  goyek.Deps{lint, test},
 	})
+
+	defineProtoTasks(&conf)
+	defineShellTasks(&conf)
+	defineMarkdownTasks(&conf)
+	defineYAMLTasks(&conf)
+	defineJSONTasks(&conf)
+	defineTOMLTasks(&conf)
+	defineSpellingTasks(&conf)
+	defineGitHubActionsTasks(&conf)
+	defineRenovateTasks(&conf)
+	defineEditorConfigTasks(&conf)
+	defineDockerTasks(&conf)
+	defineKoTasks(&conf)
+	defineLicenseHeaderTasks(&conf)
+	defineLicensesTask(&conf)
+	defineStaticcheckTask(&conf)
+	defineSecurityTask(&conf)
+	defineSecretsTask(&conf)
+	defineAPIDiffTask(&conf)
+	defineWASMTasks(&conf)
+	defineTinyGoTask(&conf)
+	definePGOTasks(&conf)
+	defineCITask(&conf)
+	defineLintConfigTask(&conf)
+	defineLintBaselineTask(&conf)
+	defineWorkspaceTasks(&conf)
+	defineModuleTasks(&conf)
+	defineHookTasks(&conf)
+	defineCommitsTask(&conf)
+	defineChangelogTask(&conf)
+	defineReleaseNotesTask(&conf)
+	defineReleaseTagTask(&conf)
+
+	goyek.Define(goyek.Task{
+		Name:  "watch",
+		Usage: "Reruns a task, test by default or set with -watch-task, when files change.",
+		Action: func(a *goyek.A) {
+			watch(a, &conf, *watchTaskFlag)
+		},
+	})
+
+	goyek.Define(goyek.Task{
+		Name:  "graph",
+		Usage: "Prints the task dependency graph as DOT or Mermaid, set with -graph-format.",
+		Action: func(a *goyek.A) {
+			printGraph(a, *graphFormatFlag, *graphTaskFlag)
+		},
+	})
+
+	goyek.Define(goyek.Task{
+		Name:  "completion",
+		Usage: "Writes a shell completion script for task names and flags, set the shell with -completion-shell.",
+		Action: func(a *goyek.A) {
+			writeCompletion(a, &conf, *completionShellFlag, *completionCommandFlag)
+		},
+	})
+
+	excluded := make(map[string]bool, len(conf.excludeTasks))
+	for _, name := range conf.excludeTasks {
+		excluded[name] = true
+	}
+	for _, t := range goyek.Tasks() {
+		if excluded[t.Name()] {
+			goyek.Undefine(t)
+		}
+	}
+
+	applyRunOn(&conf)
+	applyTaskHooks(&conf)
+	applyTaskMiddlewares(&conf)
+
+	for _, alias := range conf.aliases {
+		target := findTask(alias.task)
+		if target == nil {
+			panic(fmt.Sprintf("task %q for alias %q is not defined", alias.task, alias.name))
+		}
+		goyek.Define(goyek.Task{
+			Name:  alias.name,
+			Usage: fmt.Sprintf("Alias of %s.", alias.task),
+			Deps:  goyek.Deps{target},
+		})
+	}
+
+	if conf.defaultTask != "" {
+		t := findTask(conf.defaultTask)
+		if t == nil {
+			panic(fmt.Sprintf("default task %q is not defined", conf.defaultTask))
+		}
+		goyek.SetDefault(t)
+	}
+
+	definedConfig = &conf
+}
+
+// definedConfig is the configuration of the tasks defined by DefineTasks, for Main.
+var definedConfig *config
+
+// RegisterFormatTask adds a task as a dependency of the format aggregate task.
+// DefineTasks must be called first.
+func RegisterFormatTask(task *goyek.DefinedTask) {
+	registerDep("format", task)
+}
+
+// RegisterLintTask adds a task as a dependency of the lint aggregate task.
+// DefineTasks must be called first.
+func RegisterLintTask(task *goyek.DefinedTask) {
+	registerDep("lint", task)
+}
+
+// RegisterGenerateTask adds a task as a dependency of the generate aggregate task.
+// DefineTasks must be called first.
+func RegisterGenerateTask(task *goyek.DefinedTask) {
+	registerDep("generate", task)
+}
+
+// RegisterTestTask adds a task as a dependency of the test aggregate task, e.g.
+// for integration tests. DefineTasks must be called first.
+func RegisterTestTask(task *goyek.DefinedTask) {
+	registerDep("test", task)
+}
+
+// RegisterCheckTask adds a task as a dependency of the check aggregate task, for
+// checks that are neither lint nor tests. DefineTasks must be called first.
+func RegisterCheckTask(task *goyek.DefinedTask) {
+	registerDep("check", task)
+}
+
+func registerDep(name string, task *goyek.DefinedTask) {
+	if t := findTask(name); t != nil {
+		t.SetDeps(append(t.Deps(), task))
+		return
+	}
+	panic(fmt.Sprintf("task %q is not defined, DefineTasks must be called before registering tasks with it", name))
+}
+
+// findTask returns the defined task with the name, or nil if there is none.
+func findTask(name string) *goyek.DefinedTask {
+	for _, t := range goyek.Tasks() {
+		if t.Name() == name {
+			return t
+		}
+	}
+	return nil
 }
 
 type config struct {
+	artifactsPath        string
+	buildReport          bool
+	defaultTask          string
+	ciMode               ciMode
+	maxParallel          int
+	keepGoing            bool
+	noTaskLogs           bool
+	runOnWindows         map[string]bool
+	ignorePaths          []string
+	excludeModules       []string
+	moduleDir            string
+	affected             bool
+	affectedBaseRef      string
+	markdownTasks        bool
+	markdownFiles        []string
+	yamlTasks            bool
+	yamlFiles            []string
+	tomlTasks            bool
+	jsonTasks            bool
+	jsonSchemas          []jsonSchema
+	prettierConfigPath   string
+	yamllintConfig       string
+	spelling             bool
+	spellingDictPath     string
+	spellingFatal        bool
+	cspell               bool
+	quiet                bool
+	verbose              bool
+	lintTimeoutValue     time.Duration
+	testTimeoutValue     time.Duration
+	taskTimeouts         map[string]time.Duration
+	aliases              []taskAlias
+	taskHooks            []taskHook
+	taskMiddlewares      []func(next func(a *goyek.A)) func(a *goyek.A)
+	timingReport         bool
+	slowTaskThreshold    time.Duration
+	excludeTasks         []string
 	localPackagePrefixes []string
+	buildTargets         []string
+	vulnCheckWarnOnly    bool
+	lintSARIF            bool
+	formatCheck          bool
+	toolVersions         map[string]string
+	incrementalLint      bool
+	lintBaseRef          string
+	protoBreakingAgainst string
+	hadolintConfig       string
+	golangciConfig       string
+	golangciArgs         []string
+	lintBaseline         string
+	dockerImages         []dockerImage
+	dockerTags           []string
+	dockerBuildArgs      map[string]string
+	dockerPush           bool
+	koRepository         string
+	koBaseImage          string
+	koPush               bool
+	sbomFormats          []string
+	releaseSBOM          bool
+
+	licenseHeaderTemplate string
+	licenseHeaderPatterns []string
+	allowedLicenses       []string
+	deniedLicenses        []string
+
+	benchFilter              string
+	benchBaseline            string
+	benchRegressionThreshold float64
+
+	fuzzTime        time.Duration
+	fuzzFailOnCrash bool
+
+	coverageThreshold         float64
+	packageCoverageThresholds map[string]float64
+	coverageReports           []string
+	coverageExclude           []string
+	coverageService           string
+	coverageUploadDryRun      bool
+
+	junitReport bool
+	testRace    bool
+	testArgs    []string
+	pkgs        []string
+	vetTools    []string
+
+	staticcheck       bool
+	staticcheckChecks []string
+
+	gosec         bool
+	gosecSeverity string
+
+	secretsBaseline     string
+	secretsHistoryDepth int
+
+	apiDiff         bool
+	apiDiffWarnOnly bool
+
+	sizeBaseline            string
+	sizeRegressionThreshold float64
+
+	versionInfoPackage string
+	reproducibleBuilds bool
+	staticBinaries     bool
+
+	wasm bool
+
+	tinyGoPackages []string
+	tinyGoTarget   string
+
+	pgoPackages []string
+	pgoMaxAge   time.Duration
+
+	cleanPaths []string
+
+	ciProviders  []string
+	buildPackage string
+
+	gitHooks map[string][]string
+
+	conventionalCommits bool
+	commitTypes         []string
+	commitScopes        []string
+	commitsBaseRef      string
+
+	changelogFile          string
+	extraChangelogSections []changelogSection
+	releaseNotesHighlights string
+	releaseTagPush         bool
+	githubRelease          bool
+
+	linuxPackageFormats []string
+	packageInfo         packageInfo
+
+	brew    bool
+	brewTap string
+
+	cosignSigning   bool
+	cosignKey       string
+	cosignPublicKey string
+	cosignIdentity  string
+	cosignIssuer    string
+
+	slsaProvenance bool
+
+	condensedTestOutput bool
+	testShardIndex      int
+	testShardTotal      int
+	testRetries         int
+	flakyTestBudget     int
+
+	integrationTestTag      string
+	integrationTimeout      time.Duration
+	integrationTestsInCheck bool
+	composeFile             string
+	testServices            []testService
+}
+
+func (c *config) targets() []string {
+	if len(c.buildTargets) == 0 {
+		return []string{runtime.GOOS + "/" + runtime.GOARCH}
+	}
+	return c.buildTargets
+}
+
+// buildPkg returns the package of the build for running it from CI and hooks.
+func (c *config) buildPkg() string {
+	if c.buildPackage == "" {
+		return "./build"
+	}
+	return c.buildPackage
+}
+
+// packages returns the package patterns tested and linted by standard tasks.
+func (c *config) packages() []string {
+	if len(c.pkgs) == 0 {
+		return []string{"./..."}
+	}
+	return c.pkgs
+}
+
+// formatCheckOnly returns whether format tasks should verify formatting instead of
+// writing files, either by Option or the -check flag.
+func (c *config) formatCheckOnly() bool {
+	return c.formatCheck || *formatCheckFlag
+}
+
+func (c *config) integrationTag() string {
+	if c.integrationTestTag == "" {
+		return "integration"
+	}
+	return c.integrationTestTag
+}
+
+func (c *config) integrationTestTimeout() time.Duration {
+	if c.integrationTimeout == 0 {
+		return 30 * time.Minute
+	}
+	return c.integrationTimeout
+}
+
+func (c *config) incrementalLintBase() string {
+	if c.lintBaseRef == "" {
+		return "origin/main"
+	}
+	return c.lintBaseRef
+}
+
+func (c *config) sarifPath() string {
+	return filepath.Join(c.artifactsPath, "sarif")
+}
+
+func mkdirSARIF(a *goyek.A, c *config) bool {
+	a.Helper()
+	if err := os.MkdirAll(c.sarifPath(), 0o755); err != nil {
+		a.Errorf("failed to create SARIF directory: %v", err)
+		return false
+	}
+	return true
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
 
 // Option is a configuration option for DefineTasks.
@@ -66,6 +751,39 @@ type Option interface {
 	apply(conf *config)
 }
 
+// ArtifactsPath returns an Option to set the directory that tasks write artifacts,
+// such as coverage profiles and binaries, to. The default is "out".
+func ArtifactsPath(path string) Option {
+	return &artifactsPathOption{
+		path: path,
+	}
+}
+
+type artifactsPathOption struct {
+	path string
+}
+
+func (o *artifactsPathOption) apply(c *config) {
+	c.artifactsPath = o.path
+}
+
+// ExcludeTasks returns an Option to not define the tasks with the given names, for
+// example to replace them with custom tasks. Aggregate tasks such as lint will no
+// longer depend on excluded tasks.
+func ExcludeTasks(names ...string) Option {
+	return &excludeTasksOption{
+		names: names,
+	}
+}
+
+type excludeTasksOption struct {
+	names []string
+}
+
+func (o *excludeTasksOption) apply(c *config) {
+	c.excludeTasks = append(c.excludeTasks, o.names...)
+}
+
 // LocalPackagePrefix returns an Option to indicate the local package prefix for the project.
 // Imports from this prefix will be ordered at the end of other import groups when formatting.
 // This option can be provided multiple times to separate multiple sections, in the order
@@ -83,3 +801,1972 @@ type localPackagePrefixOption struct {
 func (o *localPackagePrefixOption) apply(c *config) {
 	c.localPackagePrefixes = append(c.localPackagePrefixes, o.localPackagePrefix)
 }
+
+// Targets returns an Option to set the platforms, in the form GOOS/GOARCH, to build
+// binaries under ./cmd for, e.g. Targets("linux/amd64", "darwin/arm64"). If not
+// provided, binaries are only built for the current platform.
+func Targets(targets ...string) Option {
+	return &targetsOption{
+		targets: targets,
+	}
+}
+
+type targetsOption struct {
+	targets []string
+}
+
+func (o *targetsOption) apply(c *config) {
+	c.buildTargets = append(c.buildTargets, o.targets...)
+}
+
+// Packages returns an Option to set the package patterns tested by test tasks and linted
+// by lint-go, e.g. Packages("./internal/...", "./pkg/..."). The default is "./...".
+func Packages(patterns ...string) Option {
+	return &packagesOption{
+		patterns: patterns,
+	}
+}
+
+type packagesOption struct {
+	patterns []string
+}
+
+func (o *packagesOption) apply(c *config) {
+	c.pkgs = append(c.pkgs, o.patterns...)
+}
+
+// VetTools returns an Option to additionally run go vet with each of the tools as
+// -vettool in lint-vet. A tool is either a path to an analyzer binary or a package with
+// a version, e.g. "example.com/analyzers/cmd/check@v1.0.0", which is installed into the
+// artifacts directory.
+func VetTools(tools ...string) Option {
+	return &vetToolsOption{
+		tools: tools,
+	}
+}
+
+type vetToolsOption struct {
+	tools []string
+}
+
+func (o *vetToolsOption) apply(c *config) {
+	c.vetTools = append(c.vetTools, o.tools...)
+}
+
+// Staticcheck returns an Option to define the lint-staticcheck task, which runs the
+// standalone staticcheck as part of lint, for projects whose golangci-lint configuration
+// does not enable it. checks are passed to -checks, e.g. "all", "-ST1000", with the
+// staticcheck defaults or any staticcheck.conf used if empty.
+func Staticcheck(checks ...string) Option {
+	return &staticcheckOption{
+		checks: checks,
+	}
+}
+
+type staticcheckOption struct {
+	checks []string
+}
+
+func (o *staticcheckOption) apply(c *config) {
+	c.staticcheck = true
+	c.staticcheckChecks = append(c.staticcheckChecks, o.checks...)
+}
+
+// Gosec returns an Option to define the lint-security task, which scans all Go modules
+// with gosec as part of lint. Only issues with at least severity, one of "low", "medium",
+// or "high", fail the task, with all issues failing it if empty.
+func Gosec(severity string) Option {
+	return &gosecOption{
+		severity: severity,
+	}
+}
+
+type gosecOption struct {
+	severity string
+}
+
+func (o *gosecOption) apply(c *config) {
+	c.gosec = true
+	c.gosecSeverity = o.severity
+}
+
+// SecretsBaseline returns an Option to set the gitleaks baseline report of lint-secrets,
+// suppressing known false positives. The default is .gitleaks-baseline.json if it exists.
+// A baseline can be generated by copying the gitleaks.json report from the artifacts
+// directory.
+func SecretsBaseline(path string) Option {
+	return &secretsBaselineOption{
+		path: path,
+	}
+}
+
+type secretsBaselineOption struct {
+	path string
+}
+
+func (o *secretsBaselineOption) apply(c *config) {
+	c.secretsBaseline = o.path
+}
+
+// SecretsHistoryDepth returns an Option to also scan the last depth commits of git
+// history in lint-secrets. By default, only the working tree is scanned.
+func SecretsHistoryDepth(depth int) Option {
+	return &secretsHistoryDepthOption{
+		depth: depth,
+	}
+}
+
+type secretsHistoryDepthOption struct {
+	depth int
+}
+
+func (o *secretsHistoryDepthOption) apply(c *config) {
+	c.secretsHistoryDepth = o.depth
+}
+
+// APIDiff returns an Option to define the lint-apidiff task, which uses gorelease to
+// fail lint when the API has incompatible changes compared to the latest released
+// version of the module without a major version bump.
+func APIDiff() Option {
+	return &apiDiffOption{}
+}
+
+type apiDiffOption struct{}
+
+func (o *apiDiffOption) apply(c *config) {
+	c.apiDiff = true
+}
+
+// APIDiffWarnOnly returns an Option to define the lint-apidiff task like APIDiff, but
+// only log incompatible changes instead of failing.
+func APIDiffWarnOnly() Option {
+	return &apiDiffWarnOnlyOption{}
+}
+
+type apiDiffWarnOnlyOption struct{}
+
+func (o *apiDiffWarnOnlyOption) apply(c *config) {
+	c.apiDiff = true
+	c.apiDiffWarnOnly = true
+}
+
+// VulnCheckWarnOnly returns an Option to report vulnerabilities found by govulncheck
+// as warnings instead of failing the lint-vuln task. This can be useful for local runs
+// where a vulnerability without an available fix should not block development.
+func VulnCheckWarnOnly() Option {
+	return &vulnCheckWarnOnlyOption{}
+}
+
+type vulnCheckWarnOnlyOption struct{}
+
+func (o *vulnCheckWarnOnlyOption) apply(c *config) {
+	c.vulnCheckWarnOnly = true
+}
+
+// CoverageThreshold returns an Option to fail the test task when the total percentage
+// of statements covered by unit tests is below the given value, e.g. CoverageThreshold(80).
+func CoverageThreshold(percent float64) Option {
+	return &coverageThresholdOption{
+		percent: percent,
+	}
+}
+
+type coverageThresholdOption struct {
+	percent float64
+}
+
+func (o *coverageThresholdOption) apply(c *config) {
+	c.coverageThreshold = o.percent
+}
+
+// PackageCoverageThreshold returns an Option to fail the test task when the percentage
+// of statements covered by unit tests in the package with the given import path is
+// below the given value. This option can be provided multiple times for different
+// packages.
+func PackageCoverageThreshold(pkg string, percent float64) Option {
+	return &packageCoverageThresholdOption{
+		pkg:     pkg,
+		percent: percent,
+	}
+}
+
+type packageCoverageThresholdOption struct {
+	pkg     string
+	percent float64
+}
+
+func (o *packageCoverageThresholdOption) apply(c *config) {
+	if c.packageCoverageThresholds == nil {
+		c.packageCoverageThresholds = map[string]float64{}
+	}
+	c.packageCoverageThresholds[o.pkg] = o.percent
+}
+
+// TestReportJUnit returns an Option to write a JUnit XML report of unit test results
+// to junit.xml in the artifacts directory, for CI systems that display test results
+// in that format.
+func TestReportJUnit() Option {
+	return &testReportJUnitOption{}
+}
+
+type testReportJUnitOption struct{}
+
+func (o *testReportJUnitOption) apply(c *config) {
+	c.junitReport = true
+}
+
+// LintSARIF returns an Option to write SARIF reports from lint tasks that support it
+// to the sarif directory under the artifacts path, in addition to console output.
+// The directory can be uploaded to code scanning services such as GitHub's.
+func LintSARIF() Option {
+	return &lintSARIFOption{}
+}
+
+type lintSARIFOption struct{}
+
+func (o *lintSARIFOption) apply(c *config) {
+	c.lintSARIF = true
+}
+
+// FormatCheckOnly returns an Option to make format tasks verify formatting without
+// writing files, failing if any file is not formatted. This can also be enabled for
+// a single run with the -check flag, e.g. in CI.
+func FormatCheckOnly() Option {
+	return &formatCheckOnlyOption{}
+}
+
+type formatCheckOnlyOption struct{}
+
+func (o *formatCheckOnlyOption) apply(c *config) {
+	c.formatCheck = true
+}
+
+// ToolVersion returns an Option to override the version of a tool run by the tasks
+// instead of the version pinned by this package. tool is the name of the tool's
+// command, e.g. "golangci-lint" or "govulncheck", and version is a version accepted
+// by go run, e.g. "v1.60.1".
+func ToolVersion(tool string, version string) Option {
+	return &toolVersionOption{
+		tool:    tool,
+		version: version,
+	}
+}
+
+// GolangCILintVersion returns an Option to override the version of golangci-lint.
+func GolangCILintVersion(version string) Option {
+	return ToolVersion("golangci-lint", version)
+}
+
+// GoFumptVersion returns an Option to override the version of gofumpt.
+func GoFumptVersion(version string) Option {
+	return ToolVersion("gofumpt", version)
+}
+
+// GciVersion returns an Option to override the version of gci.
+func GciVersion(version string) Option {
+	return ToolVersion("gci", version)
+}
+
+// GoVulnCheckVersion returns an Option to override the version of govulncheck.
+func GoVulnCheckVersion(version string) Option {
+	return ToolVersion("govulncheck", version)
+}
+
+// PrettierVersion returns an Option to override the version of go-prettier, which
+// tracks the version of prettier.
+func PrettierVersion(version string) Option {
+	return ToolVersion("prettier", version)
+}
+
+// YamllintVersion returns an Option to override the version of go-yamllint, which
+// tracks the version of yamllint.
+func YamllintVersion(version string) Option {
+	return ToolVersion("yamllint", version)
+}
+
+type toolVersionOption struct {
+	tool    string
+	version string
+}
+
+func (o *toolVersionOption) apply(c *config) {
+	if c.toolVersions == nil {
+		c.toolVersions = map[string]string{}
+	}
+	c.toolVersions[o.tool] = o.version
+}
+
+// IncrementalLint returns an Option to only report lint issues in code changed since
+// the current branch diverged from baseRef, which defaults to origin/main if empty.
+// This can speed up linting of large repositories where existing issues are tracked
+// separately. Tools checking files rather than code, like prettier and yamllint, only
+// check the changed files.
+func IncrementalLint(baseRef string) Option {
+	return &incrementalLintOption{
+		baseRef: baseRef,
+	}
+}
+
+type incrementalLintOption struct {
+	baseRef string
+}
+
+func (o *incrementalLintOption) apply(c *config) {
+	c.incrementalLint = true
+	c.lintBaseRef = o.baseRef
+}
+
+// ProtoBreakingAgainst returns an Option to set the buf input that protobuf files are
+// compared against to detect breaking changes, e.g. ".git#branch=origin/main" or a BSR
+// module such as "buf.build/acme/petapis". The default is the main branch of the git
+// repository.
+func ProtoBreakingAgainst(input string) Option {
+	return &protoBreakingAgainstOption{
+		input: input,
+	}
+}
+
+type protoBreakingAgainstOption struct {
+	input string
+}
+
+func (o *protoBreakingAgainstOption) apply(c *config) {
+	c.protoBreakingAgainst = o.input
+}
+
+// HadolintConfig returns an Option to set the path, relative to the working directory,
+// of the hadolint configuration file used when linting Dockerfiles. If not provided,
+// hadolint will use .hadolint.yaml if it exists.
+func HadolintConfig(path string) Option {
+	return &hadolintConfigOption{
+		path: path,
+	}
+}
+
+type hadolintConfigOption struct {
+	path string
+}
+
+func (o *hadolintConfigOption) apply(c *config) {
+	c.hadolintConfig = o.path
+}
+
+// DockerImage returns an Option to build a Docker image with the given name, e.g.
+// "ghcr.io/acme/server", from dockerfile with the build context directory context
+// when running the docker-build task. This option can be provided multiple times to
+// build multiple images.
+func DockerImage(name string, dockerfile string, context string) Option {
+	return &dockerImageOption{
+		image: dockerImage{
+			name:       name,
+			dockerfile: dockerfile,
+			context:    context,
+		},
+	}
+}
+
+type dockerImageOption struct {
+	image dockerImage
+}
+
+func (o *dockerImageOption) apply(c *config) {
+	c.dockerImages = append(c.dockerImages, o.image)
+}
+
+// DockerTags returns an Option to set the tags applied to Docker images built by the
+// docker-build task. If not provided, images are tagged with the version from git
+// describe and "latest".
+func DockerTags(tags ...string) Option {
+	return &dockerTagsOption{
+		tags: tags,
+	}
+}
+
+type dockerTagsOption struct {
+	tags []string
+}
+
+func (o *dockerTagsOption) apply(c *config) {
+	c.dockerTags = append(c.dockerTags, o.tags...)
+}
+
+// DockerBuildArg returns an Option to pass a build argument when building Docker
+// images with the docker-build task.
+func DockerBuildArg(key string, value string) Option {
+	return &dockerBuildArgOption{
+		key:   key,
+		value: value,
+	}
+}
+
+type dockerBuildArgOption struct {
+	key   string
+	value string
+}
+
+func (o *dockerBuildArgOption) apply(c *config) {
+	if c.dockerBuildArgs == nil {
+		c.dockerBuildArgs = map[string]string{}
+	}
+	c.dockerBuildArgs[o.key] = o.value
+}
+
+// DockerPush returns an Option to push images built by the docker-build task to their
+// registry. Images are loaded into the local Docker daemon otherwise.
+func DockerPush() Option {
+	return &dockerPushOption{}
+}
+
+type dockerPushOption struct{}
+
+func (o *dockerPushOption) apply(c *config) {
+	c.dockerPush = true
+}
+
+// KoRepository returns an Option to build container images for binaries under ./cmd
+// with ko using the docker-ko task, named under the given repository, e.g.
+// "ghcr.io/acme". Images are loaded into the local Docker daemon unless KoPush is
+// also provided.
+func KoRepository(repo string) Option {
+	return &koRepositoryOption{
+		repo: repo,
+	}
+}
+
+type koRepositoryOption struct {
+	repo string
+}
+
+func (o *koRepositoryOption) apply(c *config) {
+	c.koRepository = o.repo
+}
+
+// KoBaseImage returns an Option to set the base image of images built with ko, instead
+// of ko's default distroless image.
+func KoBaseImage(image string) Option {
+	return &koBaseImageOption{
+		image: image,
+	}
+}
+
+type koBaseImageOption struct {
+	image string
+}
+
+func (o *koBaseImageOption) apply(c *config) {
+	c.koBaseImage = o.image
+}
+
+// KoPush returns an Option to push images built with ko to the repository set with
+// KoRepository.
+func KoPush() Option {
+	return &koPushOption{}
+}
+
+type koPushOption struct{}
+
+func (o *koPushOption) apply(c *config) {
+	c.koPush = true
+}
+
+// SBOMFormats returns an Option to set the formats of documents generated by the sbom
+// task, SBOMCycloneDX and/or SBOMSPDX. The default is SBOMCycloneDX.
+func SBOMFormats(formats ...string) Option {
+	return &sbomFormatsOption{
+		formats: formats,
+	}
+}
+
+type sbomFormatsOption struct {
+	formats []string
+}
+
+func (o *sbomFormatsOption) apply(c *config) {
+	c.sbomFormats = append(c.sbomFormats, o.formats...)
+}
+
+// ReleaseSBOM returns an Option to generate SBOM documents when running the release
+// task and include them with the release archives.
+func ReleaseSBOM() Option {
+	return &releaseSBOMOption{}
+}
+
+type releaseSBOMOption struct{}
+
+func (o *releaseSBOMOption) apply(c *config) {
+	c.releaseSBOM = true
+}
+
+// LicenseHeader returns an Option to check that source files start with the license
+// header in the template file, and add it when formatting. The template is the header
+// text without comment markers, as accepted by addlicense's -f flag.
+func LicenseHeader(template string) Option {
+	return &licenseHeaderOption{
+		template: template,
+	}
+}
+
+type licenseHeaderOption struct {
+	template string
+}
+
+func (o *licenseHeaderOption) apply(c *config) {
+	c.licenseHeaderTemplate = o.template
+}
+
+// LicenseHeaderFiles returns an Option to set the patterns of file names that should
+// have a license header, e.g. "*.go". The default is Go, protobuf, and shell files.
+func LicenseHeaderFiles(patterns ...string) Option {
+	return &licenseHeaderFilesOption{
+		patterns: patterns,
+	}
+}
+
+type licenseHeaderFilesOption struct {
+	patterns []string
+}
+
+func (o *licenseHeaderFilesOption) apply(c *config) {
+	c.licenseHeaderPatterns = append(c.licenseHeaderPatterns, o.patterns...)
+}
+
+// AllowedLicenses returns an Option to fail the lint-licenses task when a dependency
+// has a license not in the list, using SPDX identifiers such as "Apache-2.0" or "MIT".
+// A report of all licenses is written to licenses.csv in the artifacts directory.
+func AllowedLicenses(licenses ...string) Option {
+	return &allowedLicensesOption{
+		licenses: licenses,
+	}
+}
+
+type allowedLicensesOption struct {
+	licenses []string
+}
+
+func (o *allowedLicensesOption) apply(c *config) {
+	c.allowedLicenses = append(c.allowedLicenses, o.licenses...)
+}
+
+// DeniedLicenses returns an Option to fail the lint-licenses task when a dependency
+// has any of the licenses, using SPDX identifiers such as "AGPL-3.0".
+func DeniedLicenses(licenses ...string) Option {
+	return &deniedLicensesOption{
+		licenses: licenses,
+	}
+}
+
+type deniedLicensesOption struct {
+	licenses []string
+}
+
+func (o *deniedLicensesOption) apply(c *config) {
+	c.deniedLicenses = append(c.deniedLicenses, o.licenses...)
+}
+
+// BenchFilter returns an Option to set the regular expression of benchmarks run by the
+// bench task, passed to go test -bench. The default runs all benchmarks.
+func BenchFilter(regex string) Option {
+	return &benchFilterOption{
+		regex: regex,
+	}
+}
+
+type benchFilterOption struct {
+	regex string
+}
+
+func (o *benchFilterOption) apply(c *config) {
+	c.benchFilter = o.regex
+}
+
+// BenchBaseline returns an Option to compare results of the bench task against the
+// baseline results in the file with benchstat. A baseline can be created by copying
+// bench.txt from the artifacts directory after running the task.
+func BenchBaseline(file string) Option {
+	return &benchBaselineOption{
+		file: file,
+	}
+}
+
+type benchBaselineOption struct {
+	file string
+}
+
+func (o *benchBaselineOption) apply(c *config) {
+	c.benchBaseline = o.file
+}
+
+// BenchRegressionThreshold returns an Option to fail the bench task when any benchmark
+// metric is significantly worse than the baseline set with BenchBaseline by more than
+// the percentage, e.g. BenchRegressionThreshold(10).
+func BenchRegressionThreshold(percent float64) Option {
+	return &benchRegressionThresholdOption{
+		percent: percent,
+	}
+}
+
+type benchRegressionThresholdOption struct {
+	percent float64
+}
+
+func (o *benchRegressionThresholdOption) apply(c *config) {
+	c.benchRegressionThreshold = o.percent
+}
+
+// FuzzTime returns an Option to set how long the fuzz task runs each fuzz test for.
+// The default is 10 seconds.
+func FuzzTime(d time.Duration) Option {
+	return &fuzzTimeOption{
+		d: d,
+	}
+}
+
+type fuzzTimeOption struct {
+	d time.Duration
+}
+
+func (o *fuzzTimeOption) apply(c *config) {
+	c.fuzzTime = o.d
+}
+
+// FuzzFailOnCrash returns an Option to fail the fuzz task when fuzzing finds a crashing
+// input. By default, crashes are reported as warnings and the crashing inputs are copied
+// to the artifacts directory, so fuzzing can run in CI without blocking it.
+func FuzzFailOnCrash() Option {
+	return &fuzzFailOnCrashOption{}
+}
+
+type fuzzFailOnCrashOption struct{}
+
+func (o *fuzzFailOnCrashOption) apply(c *config) {
+	c.fuzzFailOnCrash = true
+}
+
+// TestRace returns an Option to run unit tests with the race detector enabled. The
+// race detector requires cgo and is only supported on some platforms.
+func TestRace() Option {
+	return &testRaceOption{}
+}
+
+type testRaceOption struct{}
+
+func (o *testRaceOption) apply(c *config) {
+	c.testRace = true
+}
+
+// TestArgs returns an Option to append args to the go test command of test tasks,
+// e.g. TestArgs("-count=1", "-p", "4").
+func TestArgs(args ...string) Option {
+	return &testArgsOption{
+		args: args,
+	}
+}
+
+type testArgsOption struct {
+	args []string
+}
+
+func (o *testArgsOption) apply(c *config) {
+	c.testArgs = append(c.testArgs, o.args...)
+}
+
+// IntegrationTestTag returns an Option to set the build tag of integration tests run
+// by the test-integration task. The default is "integration". go test is run with the
+// tag for all packages, so unit tests are also run.
+func IntegrationTestTag(tag string) Option {
+	return &integrationTestTagOption{
+		tag: tag,
+	}
+}
+
+type integrationTestTagOption struct {
+	tag string
+}
+
+func (o *integrationTestTagOption) apply(c *config) {
+	c.integrationTestTag = o.tag
+}
+
+// IntegrationTestTimeout returns an Option to set the timeout of the test-integration
+// task. The default is 30 minutes.
+func IntegrationTestTimeout(d time.Duration) Option {
+	return &integrationTestTimeoutOption{
+		d: d,
+	}
+}
+
+type integrationTestTimeoutOption struct {
+	d time.Duration
+}
+
+func (o *integrationTestTimeoutOption) apply(c *config) {
+	c.integrationTimeout = o.d
+}
+
+// IntegrationTestsInCheck returns an Option to run the test-integration task as part
+// of the test and check tasks. By default, integration tests are only run when the
+// task is run directly.
+func IntegrationTestsInCheck() Option {
+	return &integrationTestsInCheckOption{}
+}
+
+type integrationTestsInCheckOption struct{}
+
+func (o *integrationTestsInCheckOption) apply(c *config) {
+	c.integrationTestsInCheck = true
+}
+
+// ComposeFile returns an Option to start the services in the Docker Compose file before
+// running integration tests and stop them afterwards. If the tests fail, service logs
+// are written to logs/compose.log in the artifacts directory.
+func ComposeFile(path string) Option {
+	return &composeFileOption{
+		path: path,
+	}
+}
+
+type composeFileOption struct {
+	path string
+}
+
+func (o *composeFileOption) apply(c *config) {
+	c.composeFile = o.path
+}
+
+// TestService returns an Option to run a container from image, e.g. "postgres:16",
+// while running tests, stopping it afterwards. env is set on the container, and all
+// ports it exposes are published. Tests are run with environment variables NAME_HOST,
+// NAME_PORT for the first exposed port, and NAME_PORT_<port> for each exposed port,
+// where NAME is the upper-cased name. If ready is not nil, it is called until it returns
+// nil before running tests. This option can be provided multiple times.
+func TestService(name string, image string, env map[string]string, ready func(ctx context.Context, info TestServiceInfo) error) Option {
+	return &testServiceOption{
+		svc: testService{
+			name:  name,
+			image: image,
+			env:   env,
+			ready: ready,
+		},
+	}
+}
+
+type testServiceOption struct {
+	svc testService
+}
+
+func (o *testServiceOption) apply(c *config) {
+	c.testServices = append(c.testServices, o.svc)
+}
+
+// CondensedTestOutput returns an Option to print a line per package when running tests
+// instead of the verbose output of every test, followed by the output of failed tests
+// and a list of the slowest tests. This can make test output on large repositories
+// easier to read.
+func CondensedTestOutput() Option {
+	return &condensedTestOutputOption{}
+}
+
+type condensedTestOutputOption struct{}
+
+func (o *condensedTestOutputOption) apply(c *config) {
+	c.condensedTestOutput = true
+}
+
+// TestShard returns an Option to only run the index'th of total shards of test packages,
+// starting from 0, to split tests across CI runners. Packages are balanced across
+// shards using durations recorded in test-timings.json in the artifacts directory,
+// which should be restored on all runners, e.g. from a cache, for an even split. If
+// not provided, the TEST_SHARD_INDEX and TEST_TOTAL_SHARDS environment variables are
+// used if set.
+func TestShard(index int, total int) Option {
+	return &testShardOption{
+		index: index,
+		total: total,
+	}
+}
+
+type testShardOption struct {
+	index int
+	total int
+}
+
+func (o *testShardOption) apply(c *config) {
+	c.testShardIndex = o.index
+	c.testShardTotal = o.total
+}
+
+// TestRetries returns an Option to rerun failed tests up to n times. Tests that pass
+// when rerun are reported as flaky in flaky-tests.json and flaky-tests.md in the
+// artifacts directory instead of failing the task.
+func TestRetries(n int) Option {
+	return &testRetriesOption{
+		n: n,
+	}
+}
+
+type testRetriesOption struct {
+	n int
+}
+
+func (o *testRetriesOption) apply(c *config) {
+	c.testRetries = o.n
+}
+
+// FlakyTestBudget returns an Option to fail the task when more than n tests are flaky
+// when running with TestRetries. By default, any number of flaky tests is allowed.
+func FlakyTestBudget(n int) Option {
+	return &flakyTestBudgetOption{
+		n: n,
+	}
+}
+
+type flakyTestBudgetOption struct {
+	n int
+}
+
+func (o *flakyTestBudgetOption) apply(c *config) {
+	c.flakyTestBudget = o.n
+}
+
+// CoverageReports returns an Option to generate reports from the unit test coverage
+// profile after running tests, CoverageReportHTML and/or CoverageReportFunc, into the
+// artifacts directory.
+func CoverageReports(reports ...string) Option {
+	return &coverageReportsOption{
+		reports: reports,
+	}
+}
+
+type coverageReportsOption struct {
+	reports []string
+}
+
+func (o *coverageReportsOption) apply(c *config) {
+	c.coverageReports = append(c.coverageReports, o.reports...)
+}
+
+// CoverageUpload returns an Option to define the coverage-upload task, which uploads
+// the merged coverage profile, or the unit test profile if not merged, to the service,
+// CoverageServiceCodecov or CoverageServiceCoveralls. Tokens are read from the
+// environment variables documented for each service.
+func CoverageUpload(service string) Option {
+	return &coverageUploadOption{
+		service: service,
+	}
+}
+
+type coverageUploadOption struct {
+	service string
+}
+
+func (o *coverageUploadOption) apply(c *config) {
+	c.coverageService = o.service
+}
+
+// CoverageUploadDryRun returns an Option to make the coverage-upload task log what would
+// be uploaded without uploading it.
+func CoverageUploadDryRun() Option {
+	return &coverageUploadDryRunOption{}
+}
+
+type coverageUploadDryRunOption struct{}
+
+func (o *coverageUploadDryRunOption) apply(c *config) {
+	c.coverageUploadDryRun = true
+}
+
+// CoverageExclude returns an Option to remove files with base names matching any of the
+// patterns, e.g. "*.pb.go" or "mock_*.go", from coverage profiles written by test tasks
+// before reports and thresholds are computed.
+func CoverageExclude(patterns ...string) Option {
+	return &coverageExcludeOption{
+		patterns: patterns,
+	}
+}
+
+type coverageExcludeOption struct {
+	patterns []string
+}
+
+func (o *coverageExcludeOption) apply(c *config) {
+	c.coverageExclude = append(c.coverageExclude, o.patterns...)
+}
+
+// SizeBaseline returns an Option to compare the sizes of binaries in size-report to the
+// sizes in file, generally a copy of size/sizes.json from the artifacts directory of a
+// previous build.
+func SizeBaseline(file string) Option {
+	return &sizeBaselineOption{
+		file: file,
+	}
+}
+
+type sizeBaselineOption struct {
+	file string
+}
+
+func (o *sizeBaselineOption) apply(c *config) {
+	c.sizeBaseline = o.file
+}
+
+// SizeRegressionThreshold returns an Option to fail the size-report task when any binary
+// grew by more than the percentage compared to the baseline set with SizeBaseline, e.g.
+// SizeRegressionThreshold(5).
+func SizeRegressionThreshold(percent float64) Option {
+	return &sizeRegressionThresholdOption{
+		percent: percent,
+	}
+}
+
+type sizeRegressionThresholdOption struct {
+	percent float64
+}
+
+func (o *sizeRegressionThresholdOption) apply(c *config) {
+	c.sizeRegressionThreshold = o.percent
+}
+
+// VersionInfo returns an Option to set the string variables Version, Commit, and Date
+// in the package pkgPath, e.g. "github.com/example/project/internal/version", when
+// building binaries. Version is the git version, Commit the full commit SHA, and Date
+// the build time in RFC 3339 format.
+func VersionInfo(pkgPath string) Option {
+	return &versionInfoOption{
+		pkgPath: pkgPath,
+	}
+}
+
+type versionInfoOption struct {
+	pkgPath string
+}
+
+func (o *versionInfoOption) apply(c *config) {
+	c.versionInfoPackage = o.pkgPath
+}
+
+// ReproducibleBuilds returns an Option to make builds of the same commit produce
+// byte-identical binaries and release archives. Binaries are built with -trimpath and
+// without VCS stamping, and times recorded in outputs, including by VersionInfo, are set
+// to SOURCE_DATE_EPOCH if set or the commit time otherwise.
+func ReproducibleBuilds() Option {
+	return &reproducibleBuildsOption{}
+}
+
+type reproducibleBuildsOption struct{}
+
+func (o *reproducibleBuildsOption) apply(c *config) {
+	c.reproducibleBuilds = true
+}
+
+// StaticBinaries returns an Option to build fully static binaries suitable for scratch
+// or distroless containers, with cgo disabled and the netgo and osusergo build tags.
+// Built Linux binaries are verified to not be dynamically linked.
+func StaticBinaries() Option {
+	return &staticBinariesOption{}
+}
+
+type staticBinariesOption struct{}
+
+func (o *staticBinariesOption) apply(c *config) {
+	c.staticBinaries = true
+}
+
+// WASM returns an Option to define the test-wasm task, which runs unit tests compiled for
+// wasip1/wasm with the wazero runtime as part of test, and the build-wasm task, which
+// builds binaries under ./cmd for wasip1/wasm.
+func WASM() Option {
+	return &wasmOption{}
+}
+
+type wasmOption struct{}
+
+func (o *wasmOption) apply(c *config) {
+	c.wasm = true
+}
+
+// TinyGo returns an Option to define the build-tinygo task, which compiles the main
+// packages, e.g. "./examples/blink", with TinyGo to catch use of features it does not
+// support. TinyGo is run with its docker image.
+func TinyGo(pkgs ...string) Option {
+	return &tinyGoOption{
+		pkgs: pkgs,
+	}
+}
+
+type tinyGoOption struct {
+	pkgs []string
+}
+
+func (o *tinyGoOption) apply(c *config) {
+	c.tinyGoPackages = append(c.tinyGoPackages, o.pkgs...)
+}
+
+// TinyGoTarget returns an Option to set the TinyGo -target used by build-tinygo, e.g.
+// "wasi" or "pico". The default is the host.
+func TinyGoTarget(target string) Option {
+	return &tinyGoTargetOption{
+		target: target,
+	}
+}
+
+type tinyGoTargetOption struct {
+	target string
+}
+
+func (o *tinyGoTargetOption) apply(c *config) {
+	c.tinyGoTarget = o.target
+}
+
+// PGO returns an Option to define tasks managing profiles for profile-guided
+// optimization of the main packages in dirs, e.g. "cmd/server". generate-pgo collects
+// CPU profiles from benchmarks, filtered by BenchFilter, and writes them merged to
+// default.pgo in each directory, which go build uses automatically. lint-pgo checks that
+// the profiles exist and are not stale.
+func PGO(dirs ...string) Option {
+	return &pgoOption{
+		dirs: dirs,
+	}
+}
+
+type pgoOption struct {
+	dirs []string
+}
+
+func (o *pgoOption) apply(c *config) {
+	c.pgoPackages = append(c.pgoPackages, o.dirs...)
+}
+
+// PGOMaxAge returns an Option to set the age after which lint-pgo considers a committed
+// profile stale. The default is 90 days.
+func PGOMaxAge(age time.Duration) Option {
+	return &pgoMaxAgeOption{
+		age: age,
+	}
+}
+
+type pgoMaxAgeOption struct {
+	age time.Duration
+}
+
+func (o *pgoMaxAgeOption) apply(c *config) {
+	c.pgoMaxAge = o.age
+}
+
+// CleanPaths returns an Option to have the clean task also delete paths, e.g. generated
+// files or caches of other tools.
+func CleanPaths(paths ...string) Option {
+	return &cleanPathsOption{
+		paths: paths,
+	}
+}
+
+type cleanPathsOption struct {
+	paths []string
+}
+
+func (o *cleanPathsOption) apply(c *config) {
+	c.cleanPaths = append(c.cleanPaths, o.paths...)
+}
+
+// GenerateCI returns an Option to define the generate-ci task, which generates workflows
+// for the CI providers, CIGitHubActions or CIGitLab, running format verification and
+// check, and release on tags if there are binaries to release. It is part of generate,
+// so generate-check fails when the workflows are out of date.
+func GenerateCI(providers ...string) Option {
+	return &generateCIOption{
+		providers: providers,
+	}
+}
+
+type generateCIOption struct {
+	providers []string
+}
+
+func (o *generateCIOption) apply(c *config) {
+	c.ciProviders = append(c.ciProviders, o.providers...)
+}
+
+// BuildPackage returns an Option to set the package of the build run by generated CI
+// workflows and git hooks, e.g. "./tools/build". The default is "./build".
+func BuildPackage(pkg string) Option {
+	return &buildPackageOption{
+		pkg: pkg,
+	}
+}
+
+type buildPackageOption struct {
+	pkg string
+}
+
+func (o *buildPackageOption) apply(c *config) {
+	c.buildPackage = o.pkg
+}
+
+// GitHook returns an Option to set the arguments the build is run with by the git hook
+// installed by install-hooks, e.g. GitHook("pre-push", "lint", "test"). If any hooks are
+// set, only they are installed, otherwise a pre-commit hook verifying formatting and a
+// pre-push hook running lint are.
+func GitHook(hook string, args ...string) Option {
+	return &gitHookOption{
+		hook: hook,
+		args: args,
+	}
+}
+
+type gitHookOption struct {
+	hook string
+	args []string
+}
+
+func (o *gitHookOption) apply(c *config) {
+	if c.gitHooks == nil {
+		c.gitHooks = map[string][]string{}
+	}
+	c.gitHooks[o.hook] = o.args
+}
+
+// ConventionalCommits returns an Option to define the lint-commits task, which checks
+// that messages of commits in the current branch follow Conventional Commits with one of
+// the types. The default types are build, chore, ci, docs, feat, fix, perf, refactor,
+// revert, style, and test.
+func ConventionalCommits(types ...string) Option {
+	return &conventionalCommitsOption{
+		types: types,
+	}
+}
+
+type conventionalCommitsOption struct {
+	types []string
+}
+
+func (o *conventionalCommitsOption) apply(c *config) {
+	c.conventionalCommits = true
+	c.commitTypes = append(c.commitTypes, o.types...)
+}
+
+// ConventionalCommitScopes returns an Option to restrict the scopes of commits checked by
+// lint-commits. Commits without a scope are always allowed.
+func ConventionalCommitScopes(scopes ...string) Option {
+	return &conventionalCommitScopesOption{
+		scopes: scopes,
+	}
+}
+
+type conventionalCommitScopesOption struct {
+	scopes []string
+}
+
+func (o *conventionalCommitScopesOption) apply(c *config) {
+	c.commitScopes = append(c.commitScopes, o.scopes...)
+}
+
+// CommitsBaseRef returns an Option to set the ref the current branch is compared to for
+// finding its commits in lint-commits. The default is origin/main.
+func CommitsBaseRef(ref string) Option {
+	return &commitsBaseRefOption{
+		ref: ref,
+	}
+}
+
+type commitsBaseRefOption struct {
+	ref string
+}
+
+func (o *commitsBaseRefOption) apply(c *config) {
+	c.commitsBaseRef = o.ref
+}
+
+// ChangelogFile returns an Option to have generate-changelog add entries to the top of
+// file, e.g. "CHANGELOG.md", instead of writing them to CHANGELOG.md in the artifacts
+// directory.
+func ChangelogFile(file string) Option {
+	return &changelogFileOption{
+		file: file,
+	}
+}
+
+type changelogFileOption struct {
+	file string
+}
+
+func (o *changelogFileOption) apply(c *config) {
+	c.changelogFile = o.file
+}
+
+// ChangelogSection returns an Option to list commits of the Conventional Commits type
+// under heading in changelogs and release notes, e.g. ChangelogSection("docs",
+// "Documentation"). By default, feat, fix, and perf commits have sections, with any
+// others listed under "Other Changes". Setting the heading of a default type renames
+// its section.
+func ChangelogSection(typ string, heading string) Option {
+	return &changelogSectionOption{
+		section: changelogSection{typ: typ, heading: heading},
+	}
+}
+
+type changelogSectionOption struct {
+	section changelogSection
+}
+
+func (o *changelogSectionOption) apply(c *config) {
+	c.extraChangelogSections = append(c.extraChangelogSections, o.section)
+}
+
+// ReleaseNotesHighlights returns an Option to include the Markdown in file as the
+// highlights of release notes rendered by release-notes.
+func ReleaseNotesHighlights(file string) Option {
+	return &releaseNotesHighlightsOption{
+		file: file,
+	}
+}
+
+type releaseNotesHighlightsOption struct {
+	file string
+}
+
+func (o *releaseNotesHighlightsOption) apply(c *config) {
+	c.releaseNotesHighlights = o.file
+}
+
+// ReleaseTagPush returns an Option to push the tag created by release-tag to origin.
+func ReleaseTagPush() Option {
+	return &releaseTagPushOption{}
+}
+
+type releaseTagPushOption struct{}
+
+func (o *releaseTagPushOption) apply(c *config) {
+	c.releaseTagPush = true
+}
+
+// GitHubRelease returns an Option to have release-tag push the tag and create a GitHub
+// release for it with the gh CLI, using the notes rendered by release-notes and
+// attaching the artifacts staged by the release task.
+func GitHubRelease() Option {
+	return &githubReleaseOption{}
+}
+
+type githubReleaseOption struct{}
+
+func (o *githubReleaseOption) apply(c *config) {
+	c.releaseTagPush = true
+	c.githubRelease = true
+}
+
+// LinuxPackages returns an Option to define the package-linux task, which packages the
+// binaries built for linux targets with nfpm into each of the formats, "deb", "rpm", or
+// "apk", installing them into /usr/bin.
+func LinuxPackages(formats ...string) Option {
+	return &linuxPackagesOption{
+		formats: formats,
+	}
+}
+
+type linuxPackagesOption struct {
+	formats []string
+}
+
+func (o *linuxPackagesOption) apply(c *config) {
+	c.linuxPackageFormats = append(c.linuxPackageFormats, o.formats...)
+}
+
+// packageInfo is metadata of packages built by package-linux.
+type packageInfo struct {
+	maintainer  string
+	description string
+	homepage    string
+	license     string
+}
+
+// PackageInfo returns an Option to set the metadata of packages built by package-linux.
+// maintainer is generally of the form "Name <email>" and license an SPDX identifier.
+func PackageInfo(maintainer string, description string, homepage string, license string) Option {
+	return &packageInfoOption{
+		info: packageInfo{
+			maintainer:  maintainer,
+			description: description,
+			homepage:    homepage,
+			license:     license,
+		},
+	}
+}
+
+type packageInfoOption struct {
+	info packageInfo
+}
+
+func (o *packageInfoOption) apply(c *config) {
+	c.packageInfo = o.info
+}
+
+// Homebrew returns an Option to define the release-brew task, which renders a Homebrew
+// formula installing the release archives for macOS and Linux targets from GitHub
+// releases of the origin repository. If tap is not empty, it is the git URL of a tap
+// repository the formula is pushed to. The description, homepage, and license are
+// taken from PackageInfo.
+func Homebrew(tap string) Option {
+	return &homebrewOption{
+		tap: tap,
+	}
+}
+
+type homebrewOption struct {
+	tap string
+}
+
+func (o *homebrewOption) apply(c *config) {
+	c.brew = true
+	c.brewTap = o.tap
+}
+
+// CosignKeyless returns an Option to define the release-sign task, which signs release
+// files and images pushed by docker-ko with cosign keyless signing, and the
+// verify-signatures task, which verifies the signatures were created by the certificate
+// identity, e.g. a workflow URL, issued by the OIDC issuer.
+func CosignKeyless(identity string, issuer string) Option {
+	return &cosignKeylessOption{
+		identity: identity,
+		issuer:   issuer,
+	}
+}
+
+type cosignKeylessOption struct {
+	identity string
+	issuer   string
+}
+
+func (o *cosignKeylessOption) apply(c *config) {
+	c.cosignSigning = true
+	c.cosignIdentity = o.identity
+	c.cosignIssuer = o.issuer
+}
+
+// CosignKey returns an Option to define the release-sign and verify-signatures tasks
+// like CosignKeyless, but signing with the private key and verifying with the public
+// key, each a file path or KMS URI supported by cosign.
+func CosignKey(privateKey string, publicKey string) Option {
+	return &cosignKeyOption{
+		privateKey: privateKey,
+		publicKey:  publicKey,
+	}
+}
+
+type cosignKeyOption struct {
+	privateKey string
+	publicKey  string
+}
+
+func (o *cosignKeyOption) apply(c *config) {
+	c.cosignSigning = true
+	c.cosignKey = o.privateKey
+	c.cosignPublicKey = o.publicKey
+}
+
+// SLSAProvenance returns an Option to define the release-provenance task, which writes a
+// SLSA provenance statement for the release files describing the builder, source commit,
+// and dependencies. The statement is written to the release artifacts directory, so it
+// is signed by release-sign and uploaded with GitHub releases created by release-tag.
+func SLSAProvenance() Option {
+	return &slsaProvenanceOption{}
+}
+
+type slsaProvenanceOption struct{}
+
+func (o *slsaProvenanceOption) apply(c *config) {
+	c.slsaProvenance = true
+}
+
+// BuildReport returns an Option to write a JSON report of the run to build-report.json
+// in the artifacts directory, with the status and duration of each task and key outputs
+// such as coverage and the number of lint issues.
+func BuildReport() Option {
+	return &buildReportOption{}
+}
+
+type buildReportOption struct{}
+
+func (o *buildReportOption) apply(c *config) {
+	c.buildReport = true
+}
+
+// TimingReport returns an Option to print a table of task durations, slowest first, at
+// the end of the run and write it to timings.txt in the artifacts directory.
+func TimingReport() Option {
+	return &timingReportOption{}
+}
+
+type timingReportOption struct{}
+
+func (o *timingReportOption) apply(c *config) {
+	c.timingReport = true
+}
+
+// SlowTaskWarning returns an Option to warn when a task takes longer than d. Slow tasks
+// are also marked in the TimingReport.
+func SlowTaskWarning(d time.Duration) Option {
+	return &slowTaskWarningOption{
+		d: d,
+	}
+}
+
+type slowTaskWarningOption struct {
+	d time.Duration
+}
+
+func (o *slowTaskWarningOption) apply(c *config) {
+	c.slowTaskThreshold = o.d
+}
+
+// DefaultTask returns an Option to set the task to run when the build is invoked
+// without any tasks, instead of printing usage.
+func DefaultTask(name string) Option {
+	return &defaultTaskOption{
+		name: name,
+	}
+}
+
+type defaultTaskOption struct {
+	name string
+}
+
+func (o *defaultTaskOption) apply(c *config) {
+	c.defaultTask = o.name
+}
+
+// Alias returns an Option to define a task named name which runs task, e.g. for short
+// names or to keep target names of a previous build system working.
+func Alias(name string, task string) Option {
+	return &aliasOption{
+		alias: taskAlias{name: name, task: task},
+	}
+}
+
+type taskAlias struct {
+	name string
+	task string
+}
+
+type aliasOption struct {
+	alias taskAlias
+}
+
+func (o *aliasOption) apply(c *config) {
+	c.aliases = append(c.aliases, o.alias)
+}
+
+// BeforeTask returns an Option to run fn before the action of the named task, e.g. to
+// warm caches before tests. If fn fails the task, its action is not run. The task must
+// have an action, so for a task that only has dependencies like test, add the hook to a
+// dependency like test-go instead.
+func BeforeTask(task string, fn func(a *goyek.A)) Option {
+	return &taskHookOption{
+		hook: taskHook{task: task, before: true, fn: fn},
+	}
+}
+
+// AfterTask returns an Option to run fn after the action of the named task, e.g. to
+// publish metrics. fn is run even if the action fails, which can be checked with
+// a.Failed.
+func AfterTask(task string, fn func(a *goyek.A)) Option {
+	return &taskHookOption{
+		hook: taskHook{task: task, fn: fn},
+	}
+}
+
+type taskHookOption struct {
+	hook taskHook
+}
+
+func (o *taskHookOption) apply(c *config) {
+	c.taskHooks = append(c.taskHooks, o.hook)
+}
+
+// TaskMiddleware returns an Option to wrap the action of every task defined by
+// DefineTasks with mw, e.g. for tracing or setting up the environment. Middlewares are
+// applied in order, the first being the outermost, and also wrap any BeforeTask and
+// AfterTask hooks.
+func TaskMiddleware(mw func(next func(a *goyek.A)) func(a *goyek.A)) Option {
+	return &taskMiddlewareOption{
+		mw: mw,
+	}
+}
+
+type taskMiddlewareOption struct {
+	mw func(next func(a *goyek.A)) func(a *goyek.A)
+}
+
+func (o *taskMiddlewareOption) apply(c *config) {
+	c.taskMiddlewares = append(c.taskMiddlewares, o.mw)
+}
+
+// ForceCI returns an Option to apply the defaults for running in CI even if no CI
+// environment is detected. In CI, formatting is only checked, colors are disabled,
+// build and JUnit reports are written, and timeouts are stricter.
+func ForceCI() Option {
+	return &ciModeOption{
+		mode: ciModeForceCI,
+	}
+}
+
+// ForceLocal returns an Option to disable the defaults for running in CI even if a CI
+// environment is detected.
+func ForceLocal() Option {
+	return &ciModeOption{
+		mode: ciModeForceLocal,
+	}
+}
+
+type ciModeOption struct {
+	mode ciMode
+}
+
+func (o *ciModeOption) apply(c *config) {
+	c.ciMode = o.mode
+}
+
+// MaxParallel returns an Option to limit the number of commands run at once to n, e.g.
+// to avoid running out of memory on small CI runners. It is also passed to go test and
+// golangci-lint to limit the packages they process in parallel. The -max-parallel flag
+// takes precedence.
+func MaxParallel(n int) Option {
+	return &maxParallelOption{
+		n: n,
+	}
+}
+
+type maxParallelOption struct {
+	n int
+}
+
+func (o *maxParallelOption) apply(c *config) {
+	c.maxParallel = o.n
+}
+
+// LintTimeout returns an Option to set the timeout of golangci-lint in the lint-go
+// task. The default is 20 minutes, or 10 minutes in CI.
+func LintTimeout(d time.Duration) Option {
+	return &lintTimeoutOption{
+		d: d,
+	}
+}
+
+type lintTimeoutOption struct {
+	d time.Duration
+}
+
+func (o *lintTimeoutOption) apply(c *config) {
+	c.lintTimeoutValue = o.d
+}
+
+// TestTimeout returns an Option to set the timeout of go test in the test-go task. The
+// default is 20 minutes, or 10 minutes in CI.
+func TestTimeout(d time.Duration) Option {
+	return &testTimeoutOption{
+		d: d,
+	}
+}
+
+type testTimeoutOption struct {
+	d time.Duration
+}
+
+func (o *testTimeoutOption) apply(c *config) {
+	c.testTimeoutValue = o.d
+}
+
+// TaskTimeout returns an Option to fail the named task if it runs longer than d,
+// killing any commands it is running. lint-go, test-go, and test-integration time out a
+// minute after the timeout of their tool by default.
+func TaskTimeout(name string, d time.Duration) Option {
+	return &taskTimeoutOption{
+		name: name,
+		d:    d,
+	}
+}
+
+type taskTimeoutOption struct {
+	name string
+	d    time.Duration
+}
+
+func (o *taskTimeoutOption) apply(c *config) {
+	if c.taskTimeouts == nil {
+		c.taskTimeouts = map[string]time.Duration{}
+	}
+	c.taskTimeouts[o.name] = o.d
+}
+
+// KeepGoing returns an Option to keep running tasks after a failure when running with
+// Main, e.g. so check reports all lint and test failures at once. Tasks depending on a
+// failed task are skipped, and failures are listed at the end of the run. It can also
+// be enabled with the -keep-going flag.
+func KeepGoing() Option {
+	return &keepGoingOption{}
+}
+
+type keepGoingOption struct{}
+
+func (o *keepGoingOption) apply(c *config) {
+	c.keepGoing = true
+}
+
+// NoTaskLogs returns an Option to disable copying the output of each task to
+// logs/<task>.log in the artifacts directory.
+func NoTaskLogs() Option {
+	return &noTaskLogsOption{}
+}
+
+type noTaskLogsOption struct{}
+
+func (o *noTaskLogsOption) apply(c *config) {
+	c.noTaskLogs = true
+}
+
+// Quiet returns an Option to only print the output of failed tasks when running with
+// Main, without the status of the run or timing reports. It can also be enabled with
+// the -q flag and takes precedence over Verbose.
+func Quiet() Option {
+	return &quietOption{}
+}
+
+type quietOption struct{}
+
+func (o *quietOption) apply(c *config) {
+	c.quiet = true
+}
+
+// Verbose returns an Option to print every task and the full output of commands when
+// running with Main, also enabling verbose output of tools like golangci-lint. It can
+// also be enabled with the -v flag.
+func Verbose() Option {
+	return &verboseOption{}
+}
+
+type verboseOption struct{}
+
+func (o *verboseOption) apply(c *config) {
+	c.verbose = true
+}
+
+// RunOn returns an Option to set whether the named task runs on Windows, for tasks that
+// cannot work there, e.g. because they call tools only available on Unix. Tasks that do
+// not run are skipped.
+func RunOn(task string, windows bool) Option {
+	return &runOnOption{
+		task:    task,
+		windows: windows,
+	}
+}
+
+type runOnOption struct {
+	task    string
+	windows bool
+}
+
+func (o *runOnOption) apply(c *config) {
+	if c.runOnWindows == nil {
+		c.runOnWindows = map[string]bool{}
+	}
+	c.runOnWindows[o.task] = o.windows
+}
+
+// IgnorePaths returns an Option to exclude files and directories matching the
+// doublestar patterns, e.g. "docs/generated/**" or "**/testdata", from tasks that find
+// files to format or lint, like Markdown, YAML, shell, and Dockerfile tasks. vendor,
+// node_modules, .git, and the artifacts directory are always ignored.
+func IgnorePaths(patterns ...string) Option {
+	return &ignorePathsOption{
+		patterns: patterns,
+	}
+}
+
+type ignorePathsOption struct {
+	patterns []string
+}
+
+func (o *ignorePathsOption) apply(c *config) {
+	c.ignorePaths = append(c.ignorePaths, o.patterns...)
+}
+
+// MarkdownTasks returns an Option to define format-markdown, formatting Markdown files
+// with prettier, when the repository contains any.
+func MarkdownTasks() Option {
+	return &markdownTasksOption{}
+}
+
+type markdownTasksOption struct{}
+
+func (o *markdownTasksOption) apply(c *config) {
+	c.markdownTasks = true
+}
+
+// YAMLTasks returns an Option to define format-yaml, formatting YAML files with
+// prettier, and lint-yaml, linting them with yamllint, when the repository contains
+// any.
+func YAMLTasks() Option {
+	return &yamlTasksOption{}
+}
+
+type yamlTasksOption struct{}
+
+func (o *yamlTasksOption) apply(c *config) {
+	c.yamlTasks = true
+}
+
+// MarkdownFiles returns an Option to set the doublestar patterns of files formatted by
+// format-markdown, replacing the default of "**/*.md". Patterns prefixed with "!"
+// exclude files, e.g. MarkdownFiles("**/*.md", "**/*.markdown", "!docs/generated/**").
+func MarkdownFiles(patterns ...string) Option {
+	return &markdownFilesOption{
+		patterns: patterns,
+	}
+}
+
+type markdownFilesOption struct {
+	patterns []string
+}
+
+func (o *markdownFilesOption) apply(c *config) {
+	c.markdownFiles = append(c.markdownFiles, o.patterns...)
+}
+
+// YAMLFiles returns an Option to set the doublestar patterns of files formatted by
+// format-yaml and linted by lint-yaml, replacing the default of "**/*.yaml" and
+// "**/*.yml". Patterns prefixed with "!" exclude files, e.g.
+// YAMLFiles("**/*.yaml", "**/*.yml.tmpl", "!charts/**").
+func YAMLFiles(patterns ...string) Option {
+	return &yamlFilesOption{
+		patterns: patterns,
+	}
+}
+
+type yamlFilesOption struct {
+	patterns []string
+}
+
+func (o *yamlFilesOption) apply(c *config) {
+	c.yamlFiles = append(c.yamlFiles, o.patterns...)
+}
+
+// TOMLTasks returns an Option to define format-toml and lint-toml, formatting and
+// linting TOML files with taplo, when the repository contains any. taplo is run with
+// Docker, which must be available.
+func TOMLTasks() Option {
+	return &tomlTasksOption{}
+}
+
+type tomlTasksOption struct{}
+
+func (o *tomlTasksOption) apply(c *config) {
+	c.tomlTasks = true
+}
+
+// JSONTasks returns an Option to define format-json and lint-json, formatting JSON files
+// with prettier and checking they are valid, when the repository contains any.
+func JSONTasks() Option {
+	return &jsonTasksOption{}
+}
+
+type jsonTasksOption struct{}
+
+func (o *jsonTasksOption) apply(c *config) {
+	c.jsonTasks = true
+}
+
+// JSONSchema returns an Option to validate JSON files matching the doublestar pattern
+// against the schema in lint-json, defined with JSONTasks, e.g.
+// JSONSchema(".github/renovate.json", "https://docs.renovatebot.com/renovate-schema.json").
+// The schema can be a local path or URL.
+func JSONSchema(pattern string, schema string) Option {
+	return &jsonSchemaOption{
+		schema: jsonSchema{pattern: pattern, schema: schema},
+	}
+}
+
+type jsonSchemaOption struct {
+	schema jsonSchema
+}
+
+func (o *jsonSchemaOption) apply(c *config) {
+	c.jsonSchemas = append(c.jsonSchemas, o.schema)
+}
+
+// PrettierConfig returns an Option to set the path, relative to the working directory,
+// of the prettier configuration file used when formatting Markdown, YAML, and JSON
+// files. If not provided, a .prettierrc or prettier.config file in the working
+// directory is used if it exists.
+func PrettierConfig(path string) Option {
+	return &prettierConfigOption{
+		path: path,
+	}
+}
+
+type prettierConfigOption struct {
+	path string
+}
+
+func (o *prettierConfigOption) apply(c *config) {
+	c.prettierConfigPath = o.path
+}
+
+// YamllintConfig returns an Option to set the path, relative to the working directory,
+// of the yamllint configuration file used when linting YAML files. If not provided,
+// .yamllint, .yamllint.yaml, or .yamllint.yml is used if it exists, or else a default
+// that disables line-length, document-start, and truthy checks of keys.
+func YamllintConfig(path string) Option {
+	return &yamllintConfigOption{
+		path: path,
+	}
+}
+
+type yamllintConfigOption struct {
+	path string
+}
+
+func (o *yamllintConfigOption) apply(c *config) {
+	c.yamllintConfig = o.path
+}
+
+// Spelling returns an Option to define the lint-spelling task, which checks Go and
+// Markdown files for common misspellings with misspell as part of lint. Misspellings
+// are only reported unless FailOnSpelling is also provided.
+func Spelling() Option {
+	return &spellingOption{}
+}
+
+type spellingOption struct{}
+
+func (o *spellingOption) apply(c *config) {
+	c.spelling = true
+}
+
+// SpellingDictionary returns an Option to set the path, relative to the working
+// directory, of the project dictionary of words accepted by lint-spelling, one per
+// line. If not provided, project-words.txt is used if it exists.
+func SpellingDictionary(path string) Option {
+	return &spellingDictionaryOption{
+		path: path,
+	}
+}
+
+type spellingDictionaryOption struct {
+	path string
+}
+
+func (o *spellingDictionaryOption) apply(c *config) {
+	c.spellingDictPath = o.path
+}
+
+// FailOnSpelling returns an Option to fail lint-spelling when it finds misspellings.
+// By default, they are only reported.
+func FailOnSpelling() Option {
+	return &failOnSpellingOption{}
+}
+
+type failOnSpellingOption struct{}
+
+func (o *failOnSpellingOption) apply(c *config) {
+	c.spellingFatal = true
+}
+
+// CSpell returns an Option to also check Markdown files for unknown words with cspell
+// in lint-spelling. cspell is run with Docker, which must be available.
+func CSpell() Option {
+	return &cspellOption{}
+}
+
+type cspellOption struct{}
+
+func (o *cspellOption) apply(c *config) {
+	c.cspell = true
+}
+
+// GolangCIConfig returns an Option to set the path, relative to the working directory,
+// of the golangci-lint configuration file used by lint-go, e.g. "build/.golangci.yml"
+// to share one configuration across the modules of a repository. If not provided,
+// golangci-lint searches for its configuration from the working directory.
+func GolangCIConfig(path string) Option {
+	return &golangciConfigOption{
+		path: path,
+	}
+}
+
+type golangciConfigOption struct {
+	path string
+}
+
+func (o *golangciConfigOption) apply(c *config) {
+	c.golangciConfig = o.path
+}
+
+// GolangCIArgs returns an Option to append args to the golangci-lint command of lint-go,
+// e.g. GolangCIArgs("--max-issues-per-linter=0", "--build-tags=integration"). Args can
+// also be added for a single run with the -golangci-args flag.
+func GolangCIArgs(args ...string) Option {
+	return &golangciArgsOption{
+		args: args,
+	}
+}
+
+type golangciArgsOption struct {
+	args []string
+}
+
+func (o *golangciArgsOption) apply(c *config) {
+	c.golangciArgs = append(c.golangciArgs, o.args...)
+}
+
+// LintBaseline returns an Option to set the path, relative to the working directory, of
+// the lint baseline written by generate-lint-baseline. If the baseline exists, lint-go
+// only fails on issues not in it, allowing golangci-lint to be adopted on a codebase
+// with many existing issues. The default is .golangci-baseline.json.
+func LintBaseline(path string) Option {
+	return &lintBaselineOption{
+		path: path,
+	}
+}
+
+type lintBaselineOption struct {
+	path string
+}
+
+func (o *lintBaselineOption) apply(c *config) {
+	c.lintBaseline = o.path
+}
+
+// ExcludeModules returns an Option to skip nested Go modules in directories matching the
+// doublestar patterns, e.g. "examples/**", when running Go tasks like lint-go, test-go,
+// and lint-go-mod. By default, all modules in the repository are included.
+func ExcludeModules(patterns ...string) Option {
+	return &excludeModulesOption{
+		patterns: patterns,
+	}
+}
+
+type excludeModulesOption struct {
+	patterns []string
+}
+
+func (o *excludeModulesOption) apply(c *config) {
+	c.excludeModules = append(c.excludeModules, o.patterns...)
+}
+
+// AffectedModules returns an Option to only run lint-go and Go tests for modules with files
+// changed since the merge base with baseRef, e.g. "origin/main", or depending on such a
+// module, reducing the work of checks in CI for repositories with many modules. If
+// baseRef is empty, origin/main is used. The mode can also be enabled for a single run
+// with the -affected flag.
+func AffectedModules(baseRef string) Option {
+	return &affectedModulesOption{
+		baseRef: baseRef,
+	}
+}
+
+type affectedModulesOption struct {
+	baseRef string
+}
+
+func (o *affectedModulesOption) apply(c *config) {
+	c.affected = true
+	c.affectedBaseRef = o.baseRef
+}
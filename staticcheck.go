@@ -0,0 +1,27 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineStaticcheckTask defines the lint-staticcheck task if enabled.
+func defineStaticcheckTask(conf *config) {
+	if !conf.staticcheck {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-staticcheck",
+		Usage: "Lints Go code with staticcheck.",
+		Action: func(a *goyek.A) {
+			cmdLine := fmt.Sprintf("go run honnef.co/go/tools/cmd/staticcheck@%s", conf.version("staticcheck", verStaticcheck))
+			if len(conf.staticcheckChecks) > 0 {
+				cmdLine += " -checks=" + shellJoin([]string{strings.Join(conf.staticcheckChecks, ",")})
+			}
+			execCmd(a, cmdLine+" "+strings.Join(conf.packages(), " "))
+		},
+	}))
+}
@@ -0,0 +1,106 @@
+package build
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// taskHook is a function run before or after the action of a task.
+type taskHook struct {
+	task   string
+	before bool
+	fn     func(a *goyek.A)
+}
+
+// applyTaskHooks wraps the actions of tasks with their configured hooks. Before
+// hooks run in order before the action, stopping the task if one fails. After hooks
+// run in order after the action even if it fails, so they can check a.Failed. Tasks
+// without an action, like test, only run after their dependencies, so before hooks
+// are not supported for them since they would run after the work they precede.
+func applyTaskHooks(conf *config) {
+	byTask := map[string][]taskHook{}
+	var names []string
+	for _, h := range conf.taskHooks {
+		if _, ok := byTask[h.task]; !ok {
+			names = append(names, h.task)
+		}
+		byTask[h.task] = append(byTask[h.task], h)
+	}
+
+	for _, name := range names {
+		t := findTask(name)
+		if t == nil {
+			panic(fmt.Sprintf("task %q for hook is not defined", name))
+		}
+		var before, after []func(a *goyek.A)
+		for _, h := range byTask[name] {
+			if h.before {
+				before = append(before, h.fn)
+			} else {
+				after = append(after, h.fn)
+			}
+		}
+		action := t.Action()
+		if action == nil && len(before) > 0 {
+			panic(fmt.Sprintf("task %q for BeforeTask has no action, add the hook to its dependencies instead", name))
+		}
+		t.SetAction(func(a *goyek.A) {
+			defer func() {
+				for _, fn := range after {
+					fn(a)
+				}
+			}()
+			for _, fn := range before {
+				fn(a)
+				if a.Failed() {
+					return
+				}
+			}
+			if action != nil {
+				action(a)
+			}
+		})
+	}
+}
+
+// applyTaskMiddlewares wraps the actions of all defined tasks with the configured
+// middlewares, the first being the outermost.
+func applyTaskMiddlewares(conf *config) {
+	if len(conf.taskMiddlewares) == 0 {
+		return
+	}
+	for _, t := range goyek.Tasks() {
+		action := t.Action()
+		if action == nil {
+			continue
+		}
+		for i := len(conf.taskMiddlewares) - 1; i >= 0; i-- {
+			action = conf.taskMiddlewares[i](action)
+		}
+		t.SetAction(action)
+	}
+}
+
+// applyRunOn skips tasks configured to not run on the current platform.
+func applyRunOn(conf *config) {
+	if runtime.GOOS != "windows" {
+		return
+	}
+	for name, windows := range conf.runOnWindows {
+		if windows {
+			continue
+		}
+		t := findTask(name)
+		if t == nil {
+			panic(fmt.Sprintf("task %q for RunOn is not defined", name))
+		}
+		if t.Action() == nil {
+			continue
+		}
+		t.SetAction(func(a *goyek.A) {
+			a.Skip("task is not supported on Windows")
+		})
+	}
+}
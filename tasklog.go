@@ -0,0 +1,68 @@
+package build
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// captureLogs returns a middleware copying the output of each task to
+// logs/<task>.log in the artifacts directory, so CI artifacts keep the full output
+// of tools even if the console log is truncated.
+func captureLogs(conf *config) goyek.Middleware {
+	return func(next goyek.Runner) goyek.Runner {
+		return func(in goyek.Input) goyek.Result {
+			if !conf.taskLogs() {
+				return next(in)
+			}
+			name := strings.NewReplacer("/", "_", ":", "_").Replace(in.TaskName) + ".log"
+			w := &lazyFile{path: filepath.Join(conf.artifactsPath, "logs", name)}
+			defer w.Close()
+			in.Output = io.MultiWriter(in.Output, w)
+			return next(in)
+		}
+	}
+}
+
+func (c *config) taskLogs() bool {
+	return !c.noTaskLogs
+}
+
+// lazyFile creates its file on the first write, so tasks without output, e.g. aggregate
+// tasks, do not leave empty logs. Errors creating or writing the file are ignored since
+// logs are only a copy of the console output.
+type lazyFile struct {
+	path string
+
+	mu  sync.Mutex
+	f   *os.File
+	err error
+}
+
+func (w *lazyFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil && w.err == nil {
+		if w.err = os.MkdirAll(filepath.Dir(w.path), 0o755); w.err == nil {
+			w.f, w.err = os.Create(w.path)
+		}
+	}
+	if w.f != nil {
+		_, _ = w.f.Write(p)
+	}
+	return len(p), nil
+}
+
+func (w *lazyFile) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f != nil {
+		_ = w.f.Close()
+	}
+}
@@ -0,0 +1,221 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// testRun describes an invocation of go test by a test task.
+type testRun struct {
+	// name identifies the run in artifact file names, empty for unit tests.
+	name    string
+	tags    string
+	timeout time.Duration
+
+	// goos and goarch set the target of the tests, the host if empty.
+	goos   string
+	goarch string
+	// exec is the program to run test binaries with, passed to go test -exec.
+	exec string
+}
+
+// artifact returns the path of the artifact file for the run, e.g. coverage.txt for
+// unit tests and coverage-integration.txt for integration tests.
+func (r testRun) artifact(conf *config, base string, ext string) string {
+	if r.name != "" {
+		base += "-" + r.name
+	}
+	return filepath.Join(conf.artifactsPath, base+ext)
+}
+
+// runTests runs unit tests, writing the coverage profile and any configured reports
+// into the artifacts directory.
+func runTests(a *goyek.A, conf *config) {
+	a.Helper()
+
+	// Without running the tests there is no coverage profile to report on.
+	if !runGoTest(a, conf, testRun{timeout: conf.testTimeout()}) || dryRun() {
+		return
+	}
+	coverage := filepath.Join(conf.artifactsPath, "coverage.txt")
+	writeCoverageReports(a, conf, coverage)
+	checkCoverage(a, conf, coverage)
+}
+
+// runGoTest runs go test for the run in each module, returning whether the tests
+// passed. The coverage profiles of nested modules are merged into the profile of the
+// run.
+func runGoTest(a *goyek.A, conf *config, run testRun) bool {
+	a.Helper()
+
+	if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+		a.Errorf("failed to create out directory: %v", err)
+		return false
+	}
+
+	baseFlags := []string{"-v", "-timeout=" + run.timeout.String()}
+	if run.tags != "" {
+		baseFlags = append(baseFlags, "-tags="+run.tags)
+	}
+	// The race detector is only supported on some host platforms.
+	if conf.testRace && run.goos == "" {
+		baseFlags = append(baseFlags, "-race")
+	}
+	if n := commands.maxParallel(); n > 0 {
+		baseFlags = append(baseFlags, fmt.Sprintf("-p=%d", n))
+	}
+	if run.exec != "" {
+		baseFlags = append(baseFlags, "-exec="+run.exec)
+	}
+	if *testRunFlag != "" {
+		baseFlags = append(baseFlags, "-run="+*testRunFlag)
+	}
+	baseFlags = append(baseFlags, conf.testArgs...)
+	coverage := run.artifact(conf, "coverage", ".txt")
+
+	dirs, err := conf.goModuleDirs()
+	if err != nil {
+		a.Errorf("failed to find Go modules: %v", err)
+		return false
+	}
+	if len(dirs) == 0 {
+		a.Log("no Go modules to test")
+		return true
+	}
+	shardIndex, shardTotal := conf.testShard()
+	if shardTotal > 0 && (shardIndex < 0 || shardIndex >= shardTotal) {
+		a.Errorf("invalid test shard %d of %d", shardIndex, shardTotal)
+		return false
+	}
+
+	env, ok := startTestServices(a, conf)
+	if !ok {
+		return false
+	}
+	var opts []cmd.Option
+	if run.goos != "" {
+		opts = append(opts, cmd.Env("GOOS", run.goos), cmd.Env("GOARCH", run.goarch))
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		opts = append(opts, cmd.Env(k, env[k]))
+	}
+
+	// Test events are needed for reports, to record timings for sharding, to find
+	// failed tests to retry, and for GitHub Actions step summaries.
+	gha := inGitHubActions()
+	var w *testEventWriter
+	if conf.junitReport || conf.condensedTestOutput || shardTotal > 0 || conf.testRetries > 0 || gha {
+		w = &testEventWriter{out: a.Output(), condensed: conf.condensedTestOutput}
+	}
+
+	multiModule := len(dirs) > 1 || len(dirs) == 1 && dirs[0] != "."
+	// profiles are the coverage profiles written, of the modules in profileDirs.
+	var profiles, profileDirs []string
+	// pkgDirs are the module directories of tested packages, for retrying their tests.
+	pkgDirs := map[string]string{}
+	var testErr error
+	for _, dir := range dirs {
+		pkgs := conf.packages()
+		if shardTotal > 0 {
+			var shardOK bool
+			pkgs, shardOK = shardPackages(a, conf, dir, run.tags, shardIndex, shardTotal)
+			if !shardOK {
+				ok = false
+				continue
+			}
+			if len(pkgs) == 0 {
+				a.Logf("no packages to test in %s in shard %d of %d", dir, shardIndex, shardTotal)
+				continue
+			}
+		}
+		// With multiple modules, each profile is kept for per-module coverage and merged
+		// into the profile of the run.
+		profile := coverage
+		var err error
+		if multiModule {
+			profile, err = filepath.Abs(filepath.Join(conf.artifactsPath, "modules", dir, filepath.Base(coverage)))
+		}
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(profile), 0o755)
+		}
+		if err != nil {
+			a.Errorf("failed to create coverage directory: %v", err)
+			ok = false
+			continue
+		}
+		flags := append([]string{"-coverprofile=" + profile, "-covermode=atomic"}, baseFlags...)
+		modOpts := moduleOpts(dir, opts)
+
+		if w == nil {
+			if !execCmd(a, goTestCommand(flags, pkgs), modOpts...) {
+				ok = false
+			}
+			profiles, profileDirs = append(profiles, profile), append(profileDirs, dir)
+			continue
+		}
+		start := len(w.Events())
+		if err := tryExec(a, goTestCommand(append([]string{"-json"}, flags...), pkgs), append(modOpts, cmd.Stdout(w))...); err != nil {
+			testErr = err
+		}
+		w.Flush()
+		for _, ev := range w.Events()[start:] {
+			pkgDirs[ev.Package] = dir
+		}
+		profiles, profileDirs = append(profiles, profile), append(profileDirs, dir)
+	}
+
+	if w != nil {
+		w.Summary()
+		if testErr != nil && conf.testRetries > 0 && retryFailedTests(a, conf, run, baseFlags, w.Events(), opts, pkgDirs) {
+			testErr = nil
+		}
+		if testErr != nil {
+			a.Error(testErr)
+			ok = false
+		}
+		if shardTotal > 0 {
+			if err := writeTestTimings(conf, w.Events()); err != nil {
+				a.Errorf("failed to write test timings: %v", err)
+			}
+		}
+		if conf.junitReport {
+			if err := writeJUnitReport(run.artifact(conf, "junit", ".xml"), w.Events()); err != nil {
+				a.Errorf("failed to write JUnit report: %v", err)
+			}
+		}
+	}
+	if len(profiles) == 0 {
+		return ok
+	}
+	if ok && !dryRun() {
+		if multiModule {
+			mergeModuleCoverage(a, conf, profileDirs, profiles, coverage)
+		}
+		excludeCoverage(a, conf, coverage)
+		recordCoverage(a, coverage)
+	}
+	if gha {
+		title := "Tests"
+		if run.name != "" {
+			title = "Tests (" + run.name + ")"
+		}
+		writeStepSummary(a, testStepSummary(title, w.Events(), coverage))
+	}
+	return ok
+}
+
+func goTestCommand(flags []string, pkgs []string) string {
+	return fmt.Sprintf("go test %s %s", shellJoin(flags), strings.Join(pkgs, " "))
+}
@@ -0,0 +1,169 @@
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// testEvent is an event emitted by go test -json, see go doc test2json.
+type testEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// testEventWriter decodes the output of go test -json, recording events and
+// writing the human-readable test output to out. If condensed, only a line per
+// package is written while running, with details of failures written by Summary.
+type testEventWriter struct {
+	out       io.Writer
+	condensed bool
+
+	mu     sync.Mutex
+	buf    []byte
+	events []testEvent
+}
+
+func (w *testEventWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.handleLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush handles any trailing output without a newline.
+func (w *testEventWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.handleLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *testEventWriter) handleLine(line []byte) {
+	var ev testEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		// Not an event, e.g. a build failure printed directly by go test.
+		_, _ = w.out.Write(append(line, '\n'))
+		return
+	}
+	w.events = append(w.events, ev)
+	if !w.condensed {
+		if ev.Output != "" {
+			_, _ = io.WriteString(w.out, ev.Output)
+		}
+		return
+	}
+	if ev.Test != "" {
+		return
+	}
+	switch ev.Action {
+	case "pass":
+		fmt.Fprintf(w.out, "ok    %s (%.2fs)\n", ev.Package, ev.Elapsed)
+	case "fail":
+		fmt.Fprintf(w.out, "FAIL  %s (%.2fs)\n", ev.Package, ev.Elapsed)
+	case "skip":
+		fmt.Fprintf(w.out, "skip  %s\n", ev.Package)
+	}
+}
+
+// numSlowestTests is the number of tests listed in the condensed summary.
+const numSlowestTests = 10
+
+// Summary writes the output of failed tests and the slowest tests to the output,
+// for condensed output.
+func (w *testEventWriter) Summary() {
+	if !w.condensed {
+		return
+	}
+
+	type result struct {
+		pkg     string
+		test    string
+		elapsed float64
+	}
+
+	output := map[[2]string]*strings.Builder{}
+	failedPkgs := map[string]bool{}
+	var failed []result
+	var tests []result
+	for _, ev := range w.Events() {
+		key := [2]string{ev.Package, ev.Test}
+		switch ev.Action {
+		case "output":
+			sb, ok := output[key]
+			if !ok {
+				sb = &strings.Builder{}
+				output[key] = sb
+			}
+			sb.WriteString(ev.Output)
+		case "pass", "fail":
+			if ev.Test == "" {
+				continue
+			}
+			tests = append(tests, result{ev.Package, ev.Test, ev.Elapsed})
+			if ev.Action == "fail" {
+				failed = append(failed, result{ev.Package, ev.Test, ev.Elapsed})
+				failedPkgs[ev.Package] = true
+			}
+		}
+	}
+	// Package failures without a failed test, e.g. build failures or panics in
+	// TestMain, only have package-level output.
+	for _, ev := range w.Events() {
+		if ev.Test == "" && ev.Action == "fail" && !failedPkgs[ev.Package] {
+			failed = append(failed, result{pkg: ev.Package, elapsed: ev.Elapsed})
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(w.out, "\n=== Failures (%d)\n", len(failed))
+		for _, r := range failed {
+			fmt.Fprintf(w.out, "--- FAIL: %s %s (%.2fs)\n", r.pkg, r.test, r.elapsed)
+			if sb, ok := output[[2]string{r.pkg, r.test}]; ok {
+				_, _ = io.WriteString(w.out, sb.String())
+			}
+		}
+	}
+
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].elapsed > tests[j].elapsed
+	})
+	if len(tests) > numSlowestTests {
+		tests = tests[:numSlowestTests]
+	}
+	if len(tests) > 0 {
+		fmt.Fprintf(w.out, "\n=== Slowest tests\n")
+		for _, r := range tests {
+			fmt.Fprintf(w.out, "%8.2fs %s %s\n", r.elapsed, r.pkg, r.test)
+		}
+	}
+}
+
+// Events returns the events decoded so far.
+func (w *testEventWriter) Events() []testEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]testEvent(nil), w.events...)
+}
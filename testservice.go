@@ -0,0 +1,143 @@
+package build
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// testServiceReadyTimeout is how long to wait for a test service to become ready.
+const testServiceReadyTimeout = time.Minute
+
+// TestServiceInfo describes a running test service container.
+type TestServiceInfo struct {
+	// Host is the host to connect to the service on.
+	Host string
+
+	// Ports maps ports exposed by the container, e.g. "5432/tcp", to the port on Host
+	// they are published to.
+	Ports map[string]string
+}
+
+type testService struct {
+	name  string
+	image string
+	env   map[string]string
+	ready func(ctx context.Context, info TestServiceInfo) error
+}
+
+// startTestServices starts the configured test service containers, stopping them when
+// the task completes, and returns the environment variables with their connection info
+// to pass to tests.
+func startTestServices(a *goyek.A, conf *config) (map[string]string, bool) {
+	a.Helper()
+
+	env := map[string]string{}
+	for _, svc := range conf.testServices {
+		args := []string{"docker", "run", "-d", "--rm", "-P"}
+		keys := make([]string, 0, len(svc.env))
+		for k := range svc.env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			args = append(args, "-e", k+"="+svc.env[k])
+		}
+		args = append(args, svc.image)
+
+		var id strings.Builder
+		if !execCmd(a, shellJoin(args), cmd.Stdout(&id)) {
+			return nil, false
+		}
+		container := strings.TrimSpace(id.String())
+		a.Cleanup(func() {
+			execCmd(a, "docker rm -f "+container)
+		})
+
+		var ports strings.Builder
+		if !execCmd(a, "docker port "+container, cmd.Stdout(&ports)) {
+			return nil, false
+		}
+		info := TestServiceInfo{
+			Host:  "localhost",
+			Ports: parseDockerPorts(ports.String()),
+		}
+
+		prefix := testServiceEnvPrefix(svc.name)
+		env[prefix+"_HOST"] = info.Host
+		exposed := make([]string, 0, len(info.Ports))
+		for p := range info.Ports {
+			exposed = append(exposed, p)
+		}
+		sort.Strings(exposed)
+		for i, p := range exposed {
+			if i == 0 {
+				env[prefix+"_PORT"] = info.Ports[p]
+			}
+			num, _, _ := strings.Cut(p, "/")
+			env[prefix+"_PORT_"+num] = info.Ports[p]
+		}
+
+		if svc.ready != nil && !waitTestServiceReady(a, svc, info) {
+			return nil, false
+		}
+	}
+	return env, true
+}
+
+func waitTestServiceReady(a *goyek.A, svc testService, info TestServiceInfo) bool {
+	a.Helper()
+
+	ctx, cancel := context.WithTimeout(a.Context(), testServiceReadyTimeout)
+	defer cancel()
+	for {
+		err := svc.ready(ctx, info)
+		if err == nil {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			a.Errorf("test service %s did not become ready: %v", svc.name, err)
+			return false
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// parseDockerPorts parses the output of docker port, with lines such as
+// "5432/tcp -> 0.0.0.0:32768".
+func parseDockerPorts(out string) map[string]string {
+	ports := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		exposed, published, ok := strings.Cut(strings.TrimSpace(line), " -> ")
+		if !ok {
+			continue
+		}
+		if _, ok := ports[exposed]; ok {
+			continue
+		}
+		if i := strings.LastIndex(published, ":"); i >= 0 {
+			ports[exposed] = published[i+1:]
+		}
+	}
+	return ports
+}
+
+// testServiceEnvPrefix returns the prefix of environment variables for the service,
+// e.g. POSTGRES for postgres.
+func testServiceEnvPrefix(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
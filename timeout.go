@@ -0,0 +1,81 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// timeoutGrace is added to the timeouts passed to tools when enforcing them on tasks,
+// so tools can report their own timeout before being killed.
+const timeoutGrace = time.Minute
+
+func (c *config) lintTimeout() time.Duration {
+	if c.lintTimeoutValue > 0 {
+		return c.lintTimeoutValue
+	}
+	return c.defaultTimeout()
+}
+
+func (c *config) testTimeout() time.Duration {
+	if c.testTimeoutValue > 0 {
+		return c.testTimeoutValue
+	}
+	return c.defaultTimeout()
+}
+
+// taskTimeout returns the timeout of the task, or 0 if it has none. Tool timeouts apply
+// to each module, so the default timeout of a Go task running all modules is scaled by
+// their number, while namespaced tasks like lint-go:libs/core run a single module.
+func (c *config) taskTimeout(name string) time.Duration {
+	if d, ok := c.taskTimeouts[name]; ok {
+		return d
+	}
+	var tool time.Duration
+	base, _, namespaced := strings.Cut(name, ":")
+	switch base {
+	case "lint-go":
+		tool = c.lintTimeout()
+	case "test", "test-go":
+		if base == "test" && !namespaced {
+			return 0
+		}
+		tool = c.testTimeout()
+	case "test-integration":
+		tool = c.integrationTestTimeout()
+	default:
+		return 0
+	}
+	if !namespaced {
+		if dirs, err := c.allGoModuleDirs(); err == nil && len(dirs) > 1 {
+			tool *= time.Duration(len(dirs))
+		}
+	}
+	return tool + timeoutGrace
+}
+
+// enforceTimeouts returns a middleware cancelling the context of tasks that run longer
+// than their timeout, which kills any commands they are running.
+func enforceTimeouts(conf *config) goyek.Middleware {
+	return func(next goyek.Runner) goyek.Runner {
+		return func(in goyek.Input) goyek.Result {
+			d := conf.taskTimeout(in.TaskName)
+			if d <= 0 {
+				return next(in)
+			}
+			ctx, cancel := context.WithTimeout(in.Context, d)
+			defer cancel()
+			in.Context = ctx
+			res := next(in)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				fmt.Fprintf(in.Output, "task %s timed out after %s\n", in.TaskName, d)
+				res.Status = goyek.StatusFailed
+			}
+			return res
+		}
+	}
+}
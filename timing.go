@@ -0,0 +1,125 @@
+package build
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// runFinished returns whether the run is over after the task, which is when a task
+// fails without -keep-going or every task requested on the command line has finished.
+// Dependencies always finish before the tasks requesting them.
+func runFinished(task string, res goyek.Result) bool {
+	if res.Status == goyek.StatusFailed && !keepGoing {
+		return true
+	}
+
+	requested := flag.Args()
+	if len(requested) == 0 {
+		if t := goyek.Default(); t != nil {
+			requested = []string{t.Name()}
+		}
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	done := make(map[string]bool, len(recorder.tasks))
+	for _, t := range recorder.tasks {
+		done[t.Name] = true
+	}
+	done[task] = true
+	for _, name := range requested {
+		if !done[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// warnSlowTask warns if the task took longer than the configured threshold, as a
+// GitHub Actions annotation when running there.
+func warnSlowTask(conf *config, out io.Writer, task string, d time.Duration) {
+	if conf.slowTaskThreshold == 0 || d <= conf.slowTaskThreshold || quiet() {
+		return
+	}
+	msg := fmt.Sprintf("task %s took %s, longer than %s", task, d.Round(time.Millisecond), conf.slowTaskThreshold)
+	if inGitHubActions() {
+		fmt.Fprintf(out, "::warning title=Slow task::%s\n", msg)
+		return
+	}
+	fmt.Fprintf(out, "WARNING: %s\n", msg)
+}
+
+// formatTimings returns a table of the tasks that ran, slowest first.
+func formatTimings(conf *config, tasks []taskRecord) string {
+	tasks = append([]taskRecord(nil), tasks...)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Duration > tasks[j].Duration
+	})
+
+	var total float64
+	width := len("TOTAL")
+	for _, t := range tasks {
+		total += t.Duration
+		if len(t.Name) > width {
+			width = len(t.Name)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n=== Task timings\n")
+	for _, t := range tasks {
+		// Aggregate tasks without actions only add noise.
+		if t.Status == "noop" {
+			continue
+		}
+		var slow string
+		if conf.slowTaskThreshold > 0 && time.Duration(t.Duration*float64(time.Second)) > conf.slowTaskThreshold {
+			slow = "  SLOW"
+		}
+		fmt.Fprintf(&sb, "%-*s  %9.2fs  %s%s\n", width, t.Name, t.Duration, t.Status, slow)
+	}
+	fmt.Fprintf(&sb, "%-*s  %9.2fs\n", width, "TOTAL", total)
+	return sb.String()
+}
+
+// reportTimings prints the timing table, unless quiet, and writes it to timings.txt
+// in the artifacts directory.
+func reportTimings(conf *config, out io.Writer) {
+	if quiet() {
+		out = io.Discard
+	}
+
+	recorder.mu.Lock()
+	tasks := append([]taskRecord(nil), recorder.tasks...)
+	recorder.mu.Unlock()
+
+	ran := false
+	for _, t := range tasks {
+		if t.Status != "noop" {
+			ran = true
+			break
+		}
+	}
+	if !ran {
+		return
+	}
+
+	table := formatTimings(conf, tasks)
+	_, _ = io.WriteString(out, table)
+
+	if err := os.MkdirAll(conf.artifactsPath, 0o755); err != nil {
+		fmt.Fprintf(out, "failed to create out directory: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(conf.artifactsPath, "timings.txt"), []byte(strings.TrimPrefix(table, "\n")), 0o644); err != nil {
+		fmt.Fprintf(out, "failed to write task timings: %v\n", err)
+	}
+}
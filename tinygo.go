@@ -0,0 +1,46 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/goyek/goyek/v2"
+)
+
+// defineTinyGoTask defines the build-tinygo task if any packages are configured.
+func defineTinyGoTask(conf *config) {
+	if len(conf.tinyGoPackages) == 0 {
+		return
+	}
+
+	goyek.Define(goyek.Task{
+		Name:  "build-tinygo",
+		Usage: "Compiles packages with TinyGo to verify compatibility.",
+		Action: func(a *goyek.A) {
+			wd, err := os.Getwd()
+			if err != nil {
+				a.Fatalf("failed to get working directory: %v", err)
+			}
+			// TinyGo is not distributed as a Go program so it is run with its official image.
+			// The image runs as a non-root user, so outputs are written within the container.
+			tinygo := fmt.Sprintf("docker run --rm -v %s:/src -w /src tinygo/tinygo:%s tinygo build", shellJoin([]string{wd}), conf.version("tinygo", verTinyGo))
+			if conf.tinyGoTarget != "" {
+				tinygo += " -target=" + conf.tinyGoTarget
+			}
+			for _, pkg := range conf.tinyGoPackages {
+				execCmd(a, fmt.Sprintf("%s -o %s %s", tinygo, path.Join("/tmp", tinyGoOutputName(pkg)), pkg))
+			}
+		},
+	})
+}
+
+// tinyGoOutputName returns the file name of the output of building pkg, the name go
+// build would use, or the project name for the current or parent directory.
+func tinyGoOutputName(pkg string) string {
+	name := importPathName(pkg)
+	if name == "." || name == ".." {
+		return projectName()
+	}
+	return name
+}
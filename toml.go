@@ -0,0 +1,51 @@
+package build
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goyek/goyek/v2"
+)
+
+var tomlPatterns = []string{"**/*.toml"}
+
+// defineTOMLTasks defines tasks for TOML files if enabled with TOMLTasks and the
+// repository contains any.
+func defineTOMLTasks(conf *config) {
+	if !conf.tomlTasks || len(globFiles(conf, tomlPatterns...)) == 0 {
+		return
+	}
+
+	RegisterFormatTask(goyek.Define(goyek.Task{
+		Name:  "format-toml",
+		Usage: "Formats TOML files with taplo.",
+		Action: func(a *goyek.A) {
+			cmdLine := taplo(a, conf) + " fmt"
+			if conf.formatCheckOnly() {
+				cmdLine += " --check"
+			}
+			execFiles(a, cmdLine, globFiles(conf, tomlPatterns...))
+		},
+	}))
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-toml",
+		Usage: "Lints TOML files with taplo.",
+		Action: func(a *goyek.A) {
+			execFiles(a, taplo(a, conf)+" lint", globFiles(conf, tomlPatterns...))
+		},
+	}))
+}
+
+// taplo returns the command line to run taplo in the working directory. taplo is not
+// distributed as a Go program so it is run with its official image, which uses it as
+// the entrypoint. A .taplo.toml in the working directory is used for configuration.
+func taplo(a *goyek.A, conf *config) string {
+	a.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		a.Fatalf("failed to get working directory: %v", err)
+	}
+	return fmt.Sprintf("docker run --rm -v %s:/work -w /work tamasfe/taplo:%s", shellJoin([]string{wd}), conf.version("taplo", verTaplo))
+}
@@ -0,0 +1,148 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goyek/goyek/v2"
+)
+
+const (
+	// CoverageServiceCodecov uploads coverage to Codecov using the CODECOV_TOKEN
+	// environment variable.
+	CoverageServiceCodecov = "codecov"
+	// CoverageServiceCoveralls uploads coverage to Coveralls using the COVERALLS_TOKEN
+	// environment variable.
+	CoverageServiceCoveralls = "coveralls"
+)
+
+// coverageUploadAttempts is the number of times to try uploading coverage, since
+// coverage services are prone to transient failures.
+const coverageUploadAttempts = 3
+
+// uploadCoverage uploads the merged coverage profile, or unit test profile if coverage
+// was not merged, to the configured service.
+func uploadCoverage(a *goyek.A, conf *config) {
+	a.Helper()
+
+	profile := filepath.Join(conf.artifactsPath, "coverage-merged.txt")
+	if !fileExists(profile) {
+		profile = filepath.Join(conf.artifactsPath, "coverage.txt")
+	}
+	if !fileExists(profile) {
+		a.Fatal("no coverage profile found, run test tasks first")
+	}
+
+	for attempt := 1; ; attempt++ {
+		var err error
+		switch conf.coverageService {
+		case CoverageServiceCodecov:
+			err = uploadCodecov(a, conf, profile)
+		case CoverageServiceCoveralls:
+			err = uploadCoveralls(a, conf, profile)
+		default:
+			a.Fatalf("unknown coverage service %q", conf.coverageService)
+		}
+		if err == nil {
+			return
+		}
+		if attempt == coverageUploadAttempts {
+			a.Fatalf("failed to upload coverage: %v", err)
+		}
+		a.Logf("failed to upload coverage, retrying: %v", err)
+		select {
+		case <-a.Context().Done():
+			a.Fatal(a.Context().Err())
+		case <-time.After(time.Duration(attempt) * 5 * time.Second):
+		}
+	}
+}
+
+func uploadCoveralls(a *goyek.A, conf *config, profile string) error {
+	a.Helper()
+
+	cmdLine := fmt.Sprintf("go run github.com/mattn/goveralls@%s %s", conf.version("goveralls", verGoveralls), shellJoin([]string{"-coverprofile=" + profile}))
+	if conf.coverageUploadDryRun {
+		cmdLine += " -dryrun"
+	}
+	// goveralls reads the repo token from COVERALLS_TOKEN.
+	return tryExec(a, cmdLine)
+}
+
+// uploadCodecov uploads the profile with Codecov's v4 upload API, which returns a
+// URL to put the report to.
+func uploadCodecov(a *goyek.A, conf *config, profile string) error {
+	a.Helper()
+
+	token := os.Getenv("CODECOV_TOKEN")
+	if token == "" && !conf.coverageUploadDryRun {
+		return fmt.Errorf("CODECOV_TOKEN is not set")
+	}
+
+	commit, err := gitCommit()
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("commit", commit)
+	params.Set("branch", gitBranch())
+	params.Set("package", "go-build")
+
+	report, err := os.ReadFile(profile)
+	if err != nil {
+		return err
+	}
+	body := append(report, []byte("\n<<<<<< EOF\n")...)
+
+	if conf.coverageUploadDryRun {
+		a.Logf("dry run: would upload %s (%d bytes) to Codecov with %s", profile, len(body), params.Encode())
+		return nil
+	}
+	params.Set("token", token)
+
+	req, err := http.NewRequestWithContext(a.Context(), http.MethodPost, "https://codecov.io/upload/v4?"+params.Encode(), http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/plain")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("codecov returned %s: %s", res.Status, resBody)
+	}
+	// The response is the URL of the report followed by the URL to upload it to.
+	lines := strings.Split(strings.TrimSpace(string(resBody)), "\n")
+	if len(lines) < 2 {
+		return fmt.Errorf("unexpected codecov response: %s", resBody)
+	}
+
+	put, err := http.NewRequestWithContext(a.Context(), http.MethodPut, strings.TrimSpace(lines[1]), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	put.Header.Set("Content-Type", "text/plain")
+	res, err = http.DefaultClient.Do(put)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("uploading report returned %s", res.Status)
+	}
+	a.Logf("uploaded coverage to %s", strings.TrimSpace(lines[0]))
+	return nil
+}
@@ -1,8 +1,44 @@
 package build
 
 const (
-	verGci          = "v0.13.4"
-	verGolangCILint = "v1.58.1"
-	verGosImports   = "v0.3.8"
-	verGoFumpt      = "v0.6.0"
+	verActionlint     = "v1.7.1"
+	verAddLicense     = "v1.1.1"
+	verBenchstat      = "v0.0.0-20240404204407-f3e401e020e4"
+	verBuf            = "v1.32.2"
+	verCosign         = "v2.2.4"
+	verCSpell         = "8.8.1"
+	verCycloneDXGoMod = "v1.6.0"
+	verEditorConfig   = "v3.0.3"
+	verGci            = "v0.13.4"
+	verGolangCILint   = "v1.58.1"
+	verGosec          = "v2.20.0"
+	verGosImports     = "v0.3.8"
+	verHadolint       = "v2.12.0"
+	verKo             = "v0.15.4"
+	verMisspell       = "v0.5.1"
+	verGitleaks       = "v8.18.2"
+	verGoLicenses     = "v1.6.0"
+	verGoFumpt        = "v0.6.0"
+	verGoveralls      = "v0.0.12"
+	verGoRelease      = "v0.0.0-20240506185415-9bf2ced13842"
+	verGoVulnCheck    = "v1.1.3"
+	verNFPM           = "v2.37.1"
+	verPrettier       = "v3.2.5"
+	verShellcheck     = "v0.10.0"
+	verShfmt          = "v3.8.0"
+	verStaticcheck    = "2023.1.7"
+	verSyft           = "v1.4.1"
+	verTaplo          = "0.9.3"
+	verTinyGo         = "0.31.2"
+	verWazero         = "v1.7.2"
+	verYamllint       = "v1.35.1"
 )
+
+// version returns the version of the tool to run, the pinned version unless
+// overridden with ToolVersion.
+func (c *config) version(tool string, pinned string) string {
+	if v, ok := c.toolVersions[tool]; ok {
+		return v
+	}
+	return pinned
+}
@@ -0,0 +1,53 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// runVet runs go vet with the default analyzers and then once with each configured
+// vet tool, since go vet only accepts a single -vettool.
+func runVet(a *goyek.A, conf *config) {
+	a.Helper()
+
+	pkgs := strings.Join(conf.packages(), " ")
+	execCmd(a, "go vet "+pkgs)
+
+	for _, tool := range conf.vetTools {
+		bin, ok := vetToolBinary(a, conf, tool)
+		if !ok {
+			continue
+		}
+		execCmd(a, fmt.Sprintf("go vet -vettool=%s %s", shellJoin([]string{bin}), pkgs))
+	}
+}
+
+// vetToolBinary returns the path to the binary of the vet tool. Tools specified as a
+// package with a version, e.g. example.com/analyzers/cmd/check@v1.0.0, are built into
+// the artifacts directory first.
+func vetToolBinary(a *goyek.A, conf *config, tool string) (string, bool) {
+	a.Helper()
+
+	pkg, version, ok := strings.Cut(tool, "@")
+	if !ok {
+		return tool, true
+	}
+	name := importPathName(pkg)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	bin, err := filepath.Abs(filepath.Join(conf.artifactsPath, "vet", name))
+	if err != nil {
+		a.Errorf("failed to resolve vet tool path: %v", err)
+		return "", false
+	}
+	if !execCmd(a, fmt.Sprintf("go install %s@%s", pkg, version), cmd.Env("GOBIN", filepath.Dir(bin))) {
+		return "", false
+	}
+	return bin, true
+}
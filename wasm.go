@@ -0,0 +1,68 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/goyek/goyek/v2"
+	"github.com/goyek/x/cmd"
+)
+
+// defineWASMTasks defines tasks for building and testing for wasip1 if enabled.
+func defineWASMTasks(conf *config) {
+	if !conf.wasm {
+		return
+	}
+
+	if dirExists("cmd") {
+		goyek.Define(goyek.Task{
+			Name:  "build-wasm",
+			Usage: "Builds binaries under ./cmd for wasip1/wasm.",
+			Action: func(a *goyek.A) {
+				out := filepath.Join(conf.artifactsPath, "bin", "wasip1_wasm") + string(filepath.Separator)
+				execCmd(a, fmt.Sprintf("go build %s ./cmd/...", shellJoin(append(buildFlags(conf), "-o", out))), cmd.Env("GOOS", "wasip1"), cmd.Env("GOARCH", "wasm"))
+			},
+		})
+	}
+
+	RegisterTestTask(goyek.Define(goyek.Task{
+		Name:  "test-wasm",
+		Usage: "Runs Go unit tests compiled for wasip1/wasm with wazero.",
+		Action: func(a *goyek.A) {
+			wazero, ok := installWazero(a, conf)
+			if !ok {
+				return
+			}
+			runGoTest(a, conf, testRun{
+				name:    "wasm",
+				timeout: conf.testTimeout(),
+				goos:    "wasip1",
+				goarch:  "wasm",
+				// Tests may access files relative to their package directory, so the host
+				// filesystem is mounted as is.
+				exec: shellJoin([]string{wazero}) + " run -mount=/:/ -env-inherit",
+			})
+		},
+	}))
+}
+
+// installWazero installs the wazero CLI into the artifacts directory, returning its path.
+// It is installed rather than run with go run since go test executes it for each package.
+func installWazero(a *goyek.A, conf *config) (string, bool) {
+	a.Helper()
+
+	name := "wazero"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	bin, err := filepath.Abs(filepath.Join(conf.artifactsPath, "wasm", name))
+	if err != nil {
+		a.Errorf("failed to resolve wazero path: %v", err)
+		return "", false
+	}
+	if !execCmd(a, "go install github.com/tetratelabs/wazero/cmd/wazero@"+conf.version("wazero", verWazero), cmd.Env("GOBIN", filepath.Dir(bin))) {
+		return "", false
+	}
+	return bin, true
+}
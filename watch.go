@@ -0,0 +1,96 @@
+package build
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/goyek/goyek/v2"
+)
+
+// watchDebounce is how long to wait for further changes before rerunning the task,
+// so that saving several files at once only triggers one run.
+const watchDebounce = 500 * time.Millisecond
+
+// watch runs the task and reruns it whenever files in the working directory change,
+// until the build is interrupted.
+func watch(a *goyek.A, conf *config, task string) {
+	a.Helper()
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		a.Fatalf("failed to create file watcher: %v", err)
+	}
+	defer w.Close()
+
+	if err := watchDirs(w, conf, "."); err != nil {
+		a.Fatalf("failed to watch files: %v", err)
+	}
+
+	// The task output is written directly to the flow output instead of the task's
+	// since the watch task never completes, which would cause it to be buffered.
+	out := goyek.Output()
+	run := func() {
+		fmt.Fprintf(out, "watch: running %s\n", task)
+		c := exec.CommandContext(a.Context(), os.Args[0], task) //nolint:gosec // reruns this build
+		c.Stdout = out
+		c.Stderr = out
+		if err := c.Run(); err != nil && a.Context().Err() == nil {
+			fmt.Fprintf(out, "watch: %s failed: %v\n", task, err)
+		}
+		fmt.Fprintln(out, "watch: waiting for changes")
+	}
+
+	run()
+	var rerun <-chan time.Time
+	for {
+		select {
+		case <-a.Context().Done():
+			return
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if ev.Op == fsnotify.Chmod || skipDir(conf, filepath.Dir(ev.Name)) || skipDir(conf, ev.Name) {
+				continue
+			}
+			if ev.Op.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if err := watchDirs(w, conf, ev.Name); err != nil {
+						a.Logf("failed to watch %s: %v", ev.Name, err)
+					}
+				}
+			}
+			rerun = time.After(watchDebounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			a.Logf("file watcher error: %v", err)
+		case <-rerun:
+			rerun = nil
+			run()
+		}
+	}
+}
+
+// watchDirs adds root and all directories under it to the watcher, since fsnotify
+// does not watch recursively.
+func watchDirs(w *fsnotify.Watcher, conf *config, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && skipDir(conf, path) {
+			return filepath.SkipDir
+		}
+		return w.Add(path)
+	})
+}
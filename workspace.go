@@ -0,0 +1,87 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goyek/goyek/v2"
+	"golang.org/x/mod/modfile"
+)
+
+// workspaceModules returns the directories of the modules used by go.work in the working
+// directory, and whether there is a workspace, which is not the case if it is disabled
+// with GOWORK=off. Modules outside the working directory and those excluded with
+// ExcludeModules are skipped, so there may be none even with a workspace.
+func workspaceModules(conf *config) ([]string, bool, error) {
+	if os.Getenv("GOWORK") == "off" {
+		return nil, false, nil
+	}
+	b, err := os.ReadFile("go.work")
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	work, err := modfile.ParseWork("go.work", b, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	var dirs []string
+	for _, u := range work.Use {
+		dir := filepath.Clean(filepath.FromSlash(u.Path))
+		if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if dir != "." && (excludedModule(conf, dir) || ignoredPath(conf, dir)) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, true, nil
+}
+
+// defineWorkspaceTasks defines the lint-go-work task if the repository has a go.work.
+func defineWorkspaceTasks(conf *config) {
+	if !fileExists("go.work") {
+		return
+	}
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-go-work",
+		Usage: "Verifies go.work uses all modules and go.work.sum is up to date.",
+		Action: func(a *goyek.A) {
+			lintGoWork(a, conf)
+		},
+	}))
+}
+
+func lintGoWork(a *goyek.A, conf *config) {
+	a.Helper()
+
+	used, _, err := workspaceModules(conf)
+	if err != nil {
+		a.Fatalf("failed to read go.work: %v", err)
+	}
+	all, err := goModules(conf)
+	if err != nil {
+		a.Fatalf("failed to find Go modules: %v", err)
+	}
+	inWork := make(map[string]bool, len(used))
+	for _, dir := range used {
+		inWork[dir] = true
+	}
+	for _, dir := range all {
+		if !inWork[dir] {
+			a.Errorf("module %s is not used by go.work, add it with go work use or skip it with ExcludeModules", dir)
+		}
+	}
+
+	// go work sync also updates the requirements of modules to the workspace's.
+	files := []string{"go.work", "go.work.sum"}
+	for _, dir := range used {
+		files = append(files, filepath.Join(dir, "go.mod"), filepath.Join(dir, "go.sum"))
+	}
+	checkUnchanged(a, conf, files, "go work sync", ".")
+}
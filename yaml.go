@@ -0,0 +1,66 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goyek/goyek/v2"
+)
+
+var defaultYAMLPatterns = []string{"**/*.yaml", "**/*.yml"}
+
+// defaultYamllintConfig is used when the repository has no yamllint configuration. It
+// relaxes the stock rules that mostly flag valid, idiomatic files, such as long lines
+// in workflow files and on: keys in GitHub Actions.
+const defaultYamllintConfig = `{extends: default, rules: {line-length: disable, document-start: disable, comments: {min-spaces-from-content: 1}, truthy: {allowed-values: ["true", "false", "on", "off", "yes", "no"], check-keys: false}}}`
+
+// yamllintConfigFiles are the names of yamllint configuration files yamllint uses
+// automatically from the working directory.
+var yamllintConfigFiles = []string{".yamllint", ".yamllint.yaml", ".yamllint.yml"}
+
+func (c *config) yamlPatterns() []string {
+	if len(c.yamlFiles) > 0 {
+		return c.yamlFiles
+	}
+	return defaultYAMLPatterns
+}
+
+// defineYAMLTasks defines tasks for YAML files if enabled with YAMLTasks and the
+// repository contains any.
+func defineYAMLTasks(conf *config) {
+	if !conf.yamlTasks || len(globFiles(conf, conf.yamlPatterns()...)) == 0 {
+		return
+	}
+
+	RegisterFormatTask(goyek.Define(goyek.Task{
+		Name:  "format-yaml",
+		Usage: "Formats YAML files with prettier.",
+		Action: func(a *goyek.A) {
+			runPrettier(a, conf, globFiles(conf, conf.yamlPatterns()...))
+		},
+	}))
+
+	RegisterLintTask(goyek.Define(goyek.Task{
+		Name:  "lint-yaml",
+		Usage: "Lints YAML files with yamllint.",
+		Action: func(a *goyek.A) {
+			yamllint := fmt.Sprintf("go run github.com/wasilibs/go-yamllint/cmd/yamllint@%s", conf.version("yamllint", verYamllint))
+			execFiles(a, yamllint+" "+conf.yamllintConfigArgs(), incrementalFiles(a, conf, globFiles(conf, conf.yamlPatterns()...)))
+		},
+	}))
+}
+
+// yamllintConfigArgs returns the yamllint arguments for its configuration, the file set
+// with YamllintConfig, the repository's own configuration, or else the default.
+func (c *config) yamllintConfigArgs() string {
+	if c.yamllintConfig != "" {
+		return "-c " + shellQuote(filepath.ToSlash(c.yamllintConfig))
+	}
+	for _, f := range yamllintConfigFiles {
+		if _, err := os.Stat(f); err == nil {
+			return "-c " + f
+		}
+	}
+	return "-d " + shellQuote(defaultYamllintConfig)
+}